@@ -0,0 +1,87 @@
+package distcron
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// nodeTTL is the time a node is considered alive after its last heartbeat when
+// using load balancing. Each node sends a heartbeat three times during this
+// period.
+const nodeTTL = 15 * time.Second
+
+// WithLoadBalancing will spread the jobs evenly over all running processes
+// instead of letting the fastest process win the lock. Every process registers
+// itself in Redis and each time a job is triggered it's assigned to one of the
+// live processes by hashing the job name and the time it was triggered. All
+// other processes skip the job without trying to take the lock. A process that
+// stops sending heartbeats is removed from the list after 15 seconds so its
+// jobs are assigned to the remaining processes. If the list of processes can't
+// be read every process falls back to racing for the lock. The registration is
+// made with Redis even if a custom Locker is used and the clocks of all
+// processes must be in sync for the assignment to be consistent.
+func (s *Schedule) WithLoadBalancing() *Schedule {
+	s.loadBalancing = true
+	return s
+}
+
+// register will keep this process registered as a live node until the context
+// is cancelled. When the context is cancelled the node is removed so its jobs
+// are assigned to other processes immediately.
+func (s *Schedule) register(ctx context.Context, pool *redis.Pool) {
+	ticker := time.NewTicker(nodeTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		if _, err := do(pool, "ZADD", s.keys.nodes(), time.Now().UnixNano(), s.nodeID); err != nil {
+			s.logger.Error(err, "could not register node", "node", s.nodeID)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if _, err := do(pool, "ZREM", s.keys.nodes(), s.nodeID); err != nil {
+				s.logger.Error(err, "could not unregister node", "node", s.nodeID)
+			}
+
+			return
+		}
+	}
+}
+
+// isAssigned reports if the job triggered at the given time is assigned to
+// this process. If the live nodes can't be read true is returned to fall back
+// to racing for the lock.
+func (s *Schedule) isAssigned(name string, triggered time.Time) bool {
+	var (
+		pool  = s.redisPool()
+		key   = s.keys.nodes()
+		alive = strconv.FormatInt(time.Now().Add(-nodeTTL).UnixNano(), 10)
+	)
+
+	if _, err := do(pool, "ZREMRANGEBYSCORE", key, "-inf", "("+alive); err != nil {
+		s.logger.Error(err, "could not remove dead nodes", s.jobFields(name, "error")...)
+		return true
+	}
+
+	nodes, err := redis.Strings(do(pool, "ZRANGEBYSCORE", key, alive, "+inf"))
+	if err != nil || len(nodes) == 0 {
+		if err != nil {
+			s.logger.Error(err, "could not get live nodes", s.jobFields(name, "error")...)
+		}
+
+		return true
+	}
+
+	sort.Strings(nodes)
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + "@" + strconv.FormatInt(triggered.Unix(), 10)))
+
+	return nodes[h.Sum32()%uint32(len(nodes))] == s.nodeID
+}
@@ -0,0 +1,104 @@
+package distcron
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// circuitBreaker holds the settings set with WithCircuitBreaker.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+}
+
+// WithCircuitBreaker will stop running a job that has failed threshold times in
+// a row in any process. The number of consecutive failures is stored in Redis
+// for each job and reset when the job succeeds. Once the threshold is reached
+// the job is skipped without trying to take the lock until the cooldown has
+// passed since the last failure. After that one trial run is allowed, if it
+// fails the job is skipped for another cooldown and if it succeeds the job runs
+// as usual again. A timeout set with WithTimeout counts as a failure. If the
+// state can't be read from Redis the job runs as usual. The state is stored in
+// Redis even if a custom Locker is used.
+func (s *Schedule) WithCircuitBreaker(threshold int, cooldown time.Duration) *Schedule {
+	if threshold <= 0 {
+		s.mu.Lock()
+		s.errs = append(s.errs, errors.New("circuit breaker threshold must be positive"))
+		s.mu.Unlock()
+
+		return s
+	}
+
+	s.circuitBreaker = &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+
+	return s
+}
+
+// isCircuitOpen reports if the job has failed too many times in a row and the
+// cooldown since the last failure hasn't passed.
+func (s *Schedule) isCircuitOpen(name string) bool {
+	if s.circuitBreaker == nil {
+		return false
+	}
+
+	values, err := redis.Int64s(do(s.redisPool(), "HMGET", s.jobKeys(name).failures(name), "count", "last"))
+	if err != nil {
+		s.logger.Error(err, "could not get consecutive failures", s.jobFields(name, "error")...)
+		return false
+	}
+
+	// Missing fields are returned as zero.
+	count, last := values[0], values[1]
+	if count < int64(s.circuitBreaker.threshold) {
+		return false
+	}
+
+	return s.now().Sub(time.Unix(0, last)) < s.circuitBreaker.cooldown
+}
+
+// recordResult will increase the number of consecutive failures for the job if
+// err is set or reset it if the job succeeded.
+func (s *Schedule) recordResult(name string, err error) {
+	if s.circuitBreaker == nil {
+		return
+	}
+
+	key := s.jobKeys(name).failures(name)
+
+	if err == nil {
+		if _, err := do(s.redisPool(), "DEL", key); err != nil {
+			s.logger.Error(err, "could not reset consecutive failures", s.jobFields(name, "error")...)
+		}
+
+		return
+	}
+
+	conn := s.redisPool().Get()
+	defer conn.Close()
+
+	count, err := redis.Int(recordFailureScript.Do(conn, key, s.now().UnixNano()))
+	if err != nil {
+		s.logger.Error(err, "could not record consecutive failures", s.jobFields(name, "error")...)
+		return
+	}
+
+	if count == s.circuitBreaker.threshold {
+		s.logger.Info(
+			"job failed too many times in a row, pausing",
+			s.jobFields(name, "circuit_open", "failures", count, "cooldown", s.circuitBreaker.cooldown.String())...,
+		)
+	}
+}
+
+// recordFailureScript will increase the number of consecutive failures, set the
+// time of the last failure and return the new count.
+var recordFailureScript = redis.NewScript(1, `
+	local count = redis.call("HINCRBY", KEYS[1], "count", 1)
+	redis.call("HSET", KEYS[1], "last", ARGV[1])
+	return count
+`)
@@ -0,0 +1,143 @@
+package distcron
+
+import (
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+)
+
+// ClearLocks will remove the keys holding the locks for the jobs with the given
+// names from Redis, including the redsync mutexes and the slots used with
+// WithMaxConcurrent. This is meant to be used by an operator to remove stale
+// locks after an incident, other processes holding a removed lock are not
+// notified and may run the job at the same time as someone else. Jobs running
+// in this process are not cleared and reported in the returned error. Names that
+// aren't added to the schedule are cleared as if they were. The keys are
+// removed from Redis even if a custom Locker is used.
+func (s *Schedule) ClearLocks(names ...string) error {
+	var result error
+
+	for _, name := range names {
+		if err := s.clearLock(name); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// ClearAllLocks works like ClearLocks for every job added to the schedule. The
+// database is also scanned for mutexes and slots with the configured prefix
+// to remove locks for jobs no longer added to the schedule. The database isn't
+// scanned when using Redis Cluster and mutexes aren't scanned for when using
+// WithKeyNamer.
+func (s *Schedule) ClearAllLocks() error {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.jobs))
+
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	s.mu.Unlock()
+
+	result := s.ClearLocks(names...)
+
+	if len(s.clusterNodes) > 0 {
+		return result
+	}
+
+	patterns := []string{s.keys.slots("*")}
+	if s.keys.namer == nil {
+		patterns = append(patterns, s.keys.mutex("*"))
+	}
+
+	for _, pattern := range patterns {
+		if err := s.clearPattern(pattern); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// clearLock will remove the keys for the lock of the job unless it's running in
+// this process.
+func (s *Schedule) clearLock(name string) error {
+	lockName := name
+
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.Name == name {
+			lockName = job.lockName()
+		}
+	}
+
+	for _, job := range s.jobs {
+		if job.lockName() == lockName && s.running[job.Name] > 0 {
+			s.mu.Unlock()
+			return fmt.Errorf("job %s is running in this process, not clearing lock", job.Name)
+		}
+	}
+	s.mu.Unlock()
+
+	k := s.jobKeys(lockName)
+
+	if _, err := do(s.redisPool(), "DEL", k.status(lockName), k.mutex(lockName), k.slots(lockName)); err != nil {
+		return fmt.Errorf("could not clear lock for job %s: %w", name, err)
+	}
+
+	s.logger.Info("cleared job lock", "job", name, "node", s.nodeID)
+
+	return nil
+}
+
+// clearPattern will scan the database for keys matching the pattern and remove
+// them. Keys for jobs running in this process are kept.
+func (s *Schedule) clearPattern(pattern string) error {
+	s.mu.Lock()
+	var lockNames []string
+
+	for _, job := range s.jobs {
+		if s.running[job.Name] > 0 {
+			lockNames = append(lockNames, job.lockName())
+		}
+	}
+	s.mu.Unlock()
+
+	running := map[string]struct{}{}
+
+	for _, lockName := range lockNames {
+		k := s.jobKeys(lockName)
+		running[k.mutex(lockName)] = struct{}{}
+		running[k.slots(lockName)] = struct{}{}
+	}
+
+	pool := s.redisPool()
+
+	for cursor := int64(0); ; {
+		reply, err := redis.Values(do(pool, "SCAN", cursor, "MATCH", pattern))
+		if err != nil {
+			return fmt.Errorf("could not scan for %s: %w", pattern, err)
+		}
+
+		var found []string
+		if _, err := redis.Scan(reply, &cursor, &found); err != nil {
+			return fmt.Errorf("could not scan for %s: %w", pattern, err)
+		}
+
+		for _, key := range found {
+			if _, ok := running[key]; ok {
+				continue
+			}
+
+			if _, err := do(pool, "DEL", key); err != nil {
+				return fmt.Errorf("could not clear %s: %w", key, err)
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package distcron
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Clock is used to get the current time and to wait for jobs to be triggered.
+// The real clock is used by default but a fake clock can be set with WithClock
+// to control time in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a timer that will send the current time on its
+	// channel after at least the given duration.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is sent when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. False is returned if the timer
+	// already fired or was stopped.
+	Stop() bool
+}
+
+// realClock is a Clock using the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// realTimer is a Timer wrapping a time.Timer.
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// WithClock sets the clock used to trigger jobs and to measure when and for how
+// long they run. Since cron doesn't support a custom clock the jobs are
+// triggered by distcron itself when a clock is set, which means that the Next
+// time of the entries returned by Entries and NextRuns will always be zero.
+// Together with the InMemoryLocker this makes it possible to test schedules
+// without waiting for the real time to pass. The real clock is used by default.
+func (s *Schedule) WithClock(clock Clock) *Schedule {
+	s.clock = clock
+	return s
+}
+
+// now returns the current time from the configured clock.
+func (s *Schedule) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+
+	return s.clock.Now()
+}
+
+// wakeClock will make the clock scheduler look for added or removed entries.
+func (s *Schedule) wakeClock() {
+	select {
+	case s.clockWake <- struct{}{}:
+	default:
+	}
+}
+
+// runClock will trigger the entries in cron with the configured clock until
+// the context is cancelled. This replaces the scheduler in cron which always
+// uses the real time. Every triggered job is added to the wait group.
+func (s *Schedule) runClock(ctx context.Context, c *cron.Cron, wg *sync.WaitGroup) {
+	next := map[cron.EntryID]time.Time{}
+
+	for {
+		var (
+			now      = s.clock.Now().In(s.location)
+			entries  = c.Entries()
+			earliest time.Time
+			seen     = map[cron.EntryID]struct{}{}
+		)
+
+		for _, entry := range entries {
+			seen[entry.ID] = struct{}{}
+
+			if _, ok := next[entry.ID]; !ok {
+				next[entry.ID] = entry.Schedule.Next(now)
+			}
+
+			// A zero time means that the entry will never run again.
+			if t := next[entry.ID]; !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+				earliest = t
+			}
+		}
+
+		for id := range next {
+			if _, ok := seen[id]; !ok {
+				delete(next, id)
+			}
+		}
+
+		// Without any entry to run we still need a timer to select on so
+		// use a long one, the same way as cron does.
+		wait := 100000 * time.Hour
+		if !earliest.IsZero() {
+			wait = earliest.Sub(now)
+		}
+
+		timer := s.clock.NewTimer(wait)
+
+		select {
+		case <-timer.C():
+			now = s.clock.Now().In(s.location)
+
+			for _, entry := range entries {
+				t := next[entry.ID]
+				if t.IsZero() || t.After(now) {
+					continue
+				}
+
+				next[entry.ID] = entry.Schedule.Next(now)
+
+				// Adding to the wait group while holding the lock ensures
+				// that Drain can wait for it once the schedule is drained.
+				s.mu.Lock()
+				if s.drained {
+					s.mu.Unlock()
+					continue
+				}
+
+				wg.Add(1)
+				s.mu.Unlock()
+
+				job := entry.WrappedJob
+
+				go func() {
+					defer wg.Done()
+					job.Run()
+				}()
+			}
+		case <-s.clockWake:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package distcron
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// clusterSlots is the number of hash slots in a Redis Cluster.
+	clusterSlots = 16384
+
+	// clusterMaxRedirects is the number of MOVED or ASK redirects followed
+	// for a single command before giving up.
+	clusterMaxRedirects = 5
+)
+
+// errClusterPipeline is returned when trying to pipeline commands to a cluster
+// since the commands might need to be sent to different nodes.
+var errClusterPipeline = errors.New("pipelining is not supported with redis cluster")
+
+// WithRedisCluster will connect to a Redis Cluster instead of a single Redis
+// database. The nodes are only used to discover the cluster, every command is
+// sent to the master owning the hash slot of its key and MOVED and ASK
+// redirects are followed. The name of the job is used as a hash tag in every
+// key belonging to the job, e.g. "GLOBAL-{name}", so all keys for a job are
+// stored in the same slot. The prefix set with WithKeyPrefix must not contain
+// any braces. The password, username and TLS configuration are used for every
+// node while the database, host, port, Unix socket and Sentinel configuration
+// are ignored.
+//
+// The mutex is taken on the master owning the slot of the job the same way as
+// with a single Redis database. This means that a lock can be lost if the
+// master fails before the key is replicated. It can't be combined with
+// WithRedisEndpoints since there's no quorum to be had within a single
+// cluster.
+func (s *Schedule) WithRedisCluster(nodes []string) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(nodes) == 0 {
+		s.errs = append(s.errs, errors.New("no redis cluster nodes configured"))
+		return s
+	}
+
+	s.clusterNodes = nodes
+	s.keys.hashTags = true
+
+	return s
+}
+
+// redisCluster routes commands to the nodes of a Redis Cluster. Each node has
+// its own pool and the slots are discovered with CLUSTER SLOTS.
+type redisCluster struct {
+	seeds   []string
+	newPool func(addr string) *redis.Pool
+
+	mu     sync.Mutex
+	loaded bool
+	slots  [clusterSlots]string
+	pools  map[string]*redis.Pool
+}
+
+// newRedisCluster creates a cluster that will be discovered from the seed
+// nodes the first time it's used.
+func (s *Schedule) newRedisCluster() *redisCluster {
+	endpoint := RedisEndpoint{
+		Username:  s.redisUsername,
+		Password:  s.redisPassword,
+		TLSConfig: s.tlsConfig,
+	}
+
+	return &redisCluster{
+		seeds: s.clusterNodes,
+		newPool: func(addr string) *redis.Pool {
+			return s.newPool(func() (redis.Conn, error) {
+				return s.dialRedis("tcp", addr, endpoint)
+			}, false)
+		},
+		pools: map[string]*redis.Pool{},
+	}
+}
+
+// conn returns a connection that will send every command to the node owning
+// its key.
+func (c *redisCluster) conn() redis.Conn {
+	return clusterConn{cluster: c}
+}
+
+// pool returns the pool for the node at addr.
+func (c *redisCluster) pool(addr string) *redis.Pool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pool, ok := c.pools[addr]
+	if !ok {
+		pool = c.newPool(addr)
+		c.pools[addr] = pool
+	}
+
+	return pool
+}
+
+// addr returns the address of the node owning the slot. If the slots can't be
+// discovered the first seed node is used and any redirect will be followed.
+func (c *redisCluster) addr(slot int) string {
+	c.mu.Lock()
+	loaded := c.loaded
+	c.mu.Unlock()
+
+	if !loaded {
+		// The error will show up when running the command since it's
+		// sent to a node that can't be reached or doesn't own the slot.
+		_ = c.refresh()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if addr := c.slots[slot]; addr != "" {
+		return addr
+	}
+
+	return c.seeds[0]
+}
+
+// refresh will ask the known nodes for the slots until one of them answers.
+func (c *redisCluster) refresh() error {
+	c.mu.Lock()
+	addrs := append([]string{}, c.seeds...)
+	for addr := range c.pools {
+		addrs = append(addrs, addr)
+	}
+	c.mu.Unlock()
+
+	var result error
+
+	for _, addr := range addrs {
+		slots, err := c.querySlots(addr)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("node %s: %w", addr, err))
+			continue
+		}
+
+		c.mu.Lock()
+		c.slots = slots
+		c.loaded = true
+		c.mu.Unlock()
+
+		return nil
+	}
+
+	return fmt.Errorf("could not get redis cluster slots: %w", result)
+}
+
+// querySlots will ask a single node for the master of every slot.
+func (c *redisCluster) querySlots(addr string) ([clusterSlots]string, error) {
+	var slots [clusterSlots]string
+
+	conn := c.pool(addr).Get()
+	defer conn.Close()
+
+	ranges, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return slots, err
+	}
+
+	for _, r := range ranges {
+		values, err := redis.Values(r, nil)
+		if err != nil || len(values) < 3 {
+			return slots, fmt.Errorf("unexpected slot range %v", r)
+		}
+
+		start, _ := redis.Int(values[0], nil)
+		end, _ := redis.Int(values[1], nil)
+
+		master, err := redis.Values(values[2], nil)
+		if err != nil || len(master) < 2 {
+			return slots, fmt.Errorf("unexpected slot master %v", values[2])
+		}
+
+		host, _ := redis.String(master[0], nil)
+		port, _ := redis.Int(master[1], nil)
+
+		// An empty host means the same host as the one we asked.
+		if host == "" {
+			host, _, _ = net.SplitHostPort(addr)
+		}
+
+		for slot := start; slot <= end && slot < clusterSlots; slot++ {
+			slots[slot] = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+	}
+
+	return slots, nil
+}
+
+// do will send the command to the node owning the key and follow any redirect.
+func (c *redisCluster) do(cmd string, args ...interface{}) (interface{}, error) {
+	var (
+		addr   = c.addr(keySlot(clusterKey(cmd, args)))
+		asking bool
+	)
+
+	for redirects := 0; ; redirects++ {
+		reply, err := c.doNode(addr, asking, cmd, args...)
+
+		var redisErr redis.Error
+		if !errors.As(err, &redisErr) {
+			if err != nil {
+				// The node might be gone after a failover so discover
+				// the slots again for the next command.
+				c.mu.Lock()
+				c.loaded = false
+				c.mu.Unlock()
+			}
+
+			return reply, err
+		}
+
+		fields := strings.Fields(string(redisErr))
+		if len(fields) != 3 || redirects >= clusterMaxRedirects {
+			return reply, err
+		}
+
+		switch fields[0] {
+		case "MOVED":
+			slot, _ := strconv.Atoi(fields[1])
+			if slot >= 0 && slot < clusterSlots {
+				c.mu.Lock()
+				c.slots[slot] = fields[2]
+				c.mu.Unlock()
+			}
+
+			addr, asking = fields[2], false
+		case "ASK":
+			addr, asking = fields[2], true
+		default:
+			return reply, err
+		}
+	}
+}
+
+// doNode sends the command to the node at addr. If asking is true the command
+// is preceded by ASKING to follow an ASK redirect.
+func (c *redisCluster) doNode(addr string, asking bool, cmd string, args ...interface{}) (interface{}, error) {
+	conn := c.pool(addr).Get()
+	defer conn.Close()
+
+	if asking {
+		if _, err := conn.Do("ASKING"); err != nil {
+			return nil, err
+		}
+	}
+
+	return conn.Do(cmd, args...)
+}
+
+// close will close the pools for all nodes.
+func (c *redisCluster) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, pool := range c.pools {
+		_ = pool.Close()
+	}
+
+	c.pools = map[string]*redis.Pool{}
+	c.loaded = false
+}
+
+// clusterConn is a redis.Conn sending every command to the cluster. It holds no
+// connection itself so it can be used with a regular redis.Pool.
+type clusterConn struct {
+	cluster *redisCluster
+}
+
+func (clusterConn) Close() error {
+	return nil
+}
+
+func (clusterConn) Err() error {
+	return nil
+}
+
+func (c clusterConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	// An empty command is used by the pool to flush the connection.
+	if cmd == "" {
+		return nil, nil
+	}
+
+	return c.cluster.do(cmd, args...)
+}
+
+func (clusterConn) Send(string, ...interface{}) error {
+	return errClusterPipeline
+}
+
+func (clusterConn) Flush() error {
+	return errClusterPipeline
+}
+
+func (clusterConn) Receive() (interface{}, error) {
+	return nil, errClusterPipeline
+}
+
+// clusterKey returns the key used to route the command. Scripts have the number
+// of keys as the second argument followed by the keys. Commands without a key
+// return an empty string and are sent to any node.
+func clusterKey(cmd string, args []interface{}) string {
+	index := 0
+
+	switch strings.ToUpper(cmd) {
+	case "EVAL", "EVALSHA":
+		index = 2
+	case "PING", "INFO", "ROLE", "CLUSTER", "SCRIPT":
+		return ""
+	}
+
+	if len(args) <= index {
+		return ""
+	}
+
+	switch key := args[index].(type) {
+	case string:
+		return key
+	case []byte:
+		return string(key)
+	default:
+		return fmt.Sprint(key)
+	}
+}
+
+// keySlot returns the hash slot of the key. If the key contains a hash tag only
+// the tag is hashed.
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	return int(crc16(key) % clusterSlots)
+}
+
+// crc16 is the CRC16 XMODEM checksum used by Redis Cluster.
+func crc16(s string) uint16 {
+	var crc uint16
+
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return crc
+}
@@ -0,0 +1,102 @@
+package distcron
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// JobSpec is the definition of a job loaded with LoadJobs.
+type JobSpec struct {
+	Name    string `json:"name" yaml:"name"`
+	Spec    string `json:"spec" yaml:"spec"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+}
+
+// LoadJobs will read a list of job definitions in the given format which is
+// either "json" or "yaml". Each job is an object with the keys name, spec and
+// enabled. A job without the enabled key is enabled. Use BindJobs to add the
+// loaded jobs to a schedule.
+func LoadJobs(r io.Reader, format string) ([]JobSpec, error) {
+	var raw []struct {
+		Name    string `json:"name" yaml:"name"`
+		Spec    string `json:"spec" yaml:"spec"`
+		Enabled *bool  `json:"enabled" yaml:"enabled"`
+	}
+
+	var err error
+
+	switch format {
+	case "json":
+		err = json.NewDecoder(r).Decode(&raw)
+	case "yaml":
+		err = yaml.NewDecoder(r).Decode(&raw)
+	default:
+		return nil, fmt.Errorf("unsupported job format %q", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not decode jobs: %w", err)
+	}
+
+	specs := make([]JobSpec, 0, len(raw))
+
+	for _, j := range raw {
+		specs = append(specs, JobSpec{
+			Name:    j.Name,
+			Spec:    j.Spec,
+			Enabled: j.Enabled == nil || *j.Enabled,
+		})
+	}
+
+	return specs, nil
+}
+
+// BindJobs will add every job from specs with the function with the same name in
+// funcs. Jobs that aren't enabled are added but disabled, see DisableJob. It's an
+// error if a job doesn't have a function or if there's a function without a job
+// and the errors will be returned when calling Run.
+func (s *Schedule) BindJobs(specs []JobSpec, funcs map[string]func()) *Schedule {
+	bound := map[string]struct{}{}
+
+	for _, spec := range specs {
+		f, ok := funcs[spec.Name]
+		if !ok {
+			s.mu.Lock()
+			s.errs = append(s.errs, fmt.Errorf("no function bound to job %s", spec.Name))
+			s.mu.Unlock()
+
+			continue
+		}
+
+		bound[spec.Name] = struct{}{}
+
+		s.AddJob(spec.Spec, spec.Name, f)
+
+		if !spec.Enabled {
+			s.DisableJob(spec.Name)
+		}
+	}
+
+	unknown := []string{}
+
+	for name := range funcs {
+		if _, ok := bound[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	// Sort the names to always report the errors in the same order.
+	sort.Strings(unknown)
+
+	s.mu.Lock()
+	for _, name := range unknown {
+		s.errs = append(s.errs, fmt.Errorf("function bound to unknown job %s", name))
+	}
+	s.mu.Unlock()
+
+	return s
+}
@@ -0,0 +1,85 @@
+package distcron
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadJobs(t *testing.T) {
+	expected := []JobSpec{
+		{Name: "first", Spec: "@hourly", Enabled: true},
+		{Name: "second", Spec: "*/5 * * * *", Enabled: false},
+	}
+
+	cases := []struct {
+		format string
+		input  string
+	}{
+		{
+			format: "json",
+			input: `[
+				{"name": "first", "spec": "@hourly"},
+				{"name": "second", "spec": "*/5 * * * *", "enabled": false}
+			]`,
+		},
+		{
+			format: "yaml",
+			input: `
+- name: first
+  spec: "@hourly"
+- name: second
+  spec: "*/5 * * * *"
+  enabled: false
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.format, func(t *testing.T) {
+			specs, err := LoadJobs(strings.NewReader(tc.input), tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(specs, expected) {
+				t.Fatalf("expected %+v, got %+v", expected, specs)
+			}
+		})
+	}
+
+	if _, err := LoadJobs(strings.NewReader(""), "toml"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+
+	if _, err := LoadJobs(strings.NewReader("name: [first"), "yaml"); err == nil {
+		t.Fatal("expected an error for invalid yaml")
+	}
+}
+
+func TestBindJobs(t *testing.T) {
+	specs := []JobSpec{
+		{Name: "bound", Spec: "@hourly", Enabled: true},
+		{Name: "unbound", Spec: "@hourly", Enabled: true},
+	}
+
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		BindJobs(specs, map[string]func(){
+			"bound":   func() {},
+			"unknown": func() {},
+		})
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("expected an error for unbound and unknown jobs")
+	}
+
+	for _, name := range []string{"unbound", "unknown"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected error to mention %s, got %v", name, err)
+		}
+	}
+}
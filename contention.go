@@ -0,0 +1,38 @@
+package distcron
+
+import "time"
+
+// lostLogInterval is the minimum time between two logs about losing the lock
+// for the same job. Losing the lock is expected when running multiple processes
+// so logging it every time a job is triggered would flood the logs.
+const lostLogInterval = 5 * time.Minute
+
+// logLost will log that the lock for the job was taken by another process. The
+// log is only written once every lostLogInterval for each job and includes the
+// number of times the lock was lost since the last log.
+func (s *Schedule) logLost(locker Locker, name, lockName string) {
+	now := time.Now()
+
+	s.mu.Lock()
+	if s.lostLogged == nil {
+		s.lostLogged = map[string]time.Time{}
+		s.lostCount = map[string]int{}
+	}
+
+	s.lostCount[name]++
+	count := s.lostCount[name]
+
+	if last, ok := s.lostLogged[name]; ok && now.Sub(last) < lostLogInterval {
+		s.mu.Unlock()
+		return
+	}
+
+	s.lostLogged[name] = now
+	s.lostCount[name] = 0
+	s.mu.Unlock()
+
+	s.logger.Info(
+		"wasn't first to take the job, aborting",
+		s.jobFields(name, "lost", "holder", s.lockHolder(locker, lockName), "times", count)...,
+	)
+}
@@ -0,0 +1,574 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/robfig/cron/v3"
+)
+
+// newTestSchedule starts a Redis test server and returns it together with a
+// schedule connected to it. The server must be closed by the caller.
+func newTestSchedule(t *testing.T) (*miniredis.Miniredis, *Schedule) {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("could not start redis: %v", err)
+	}
+
+	port, err := strconv.Atoi(mr.Port())
+	if err != nil {
+		t.Fatalf("invalid redis port: %v", err)
+	}
+
+	s := New().
+		WithRedisHost(mr.Host()).
+		WithRedisPort(port).
+		WithNodeID("node-1")
+
+	return mr, s
+}
+
+// startSchedule will run the schedule in a new goroutine and return when it's
+// started. The schedule is stopped and the error from RunContext is returned
+// when the returned function is called.
+func startSchedule(t *testing.T, s *Schedule) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.RunContext(ctx)
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		s.mu.Lock()
+		started := s.cron != nil
+		s.mu.Unlock()
+
+		if started {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatalf("schedule never started: %v", <-done)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+// newTestLocker returns a Redis locker for the schedule.
+func newTestLocker(s *Schedule) *redisLocker {
+	return s.newRedisLocker(context.Background(), s.redisPool(), s.redisPools())
+}
+
+func TestParserFields(t *testing.T) {
+	base := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description string
+		seconds     bool
+		spec        string
+		next        time.Time
+		valid       bool
+	}{
+		{
+			description: "five fields",
+			spec:        "*/5 * * * *",
+			next:        base.Add(5 * time.Minute),
+			valid:       true,
+		},
+		{
+			description: "six fields without seconds",
+			spec:        "*/30 * * * * *",
+		},
+		{
+			description: "six fields with seconds",
+			seconds:     true,
+			spec:        "*/30 * * * * *",
+			next:        base.Add(30 * time.Second),
+			valid:       true,
+		},
+		{
+			description: "six fields with trailing space",
+			seconds:     true,
+			spec:        "*/30 * * * * * ",
+			next:        base.Add(30 * time.Second),
+			valid:       true,
+		},
+		{
+			description: "every descriptor",
+			spec:        "@every 1m",
+			next:        base.Add(time.Minute),
+			valid:       true,
+		},
+		{
+			description: "every descriptor with seconds",
+			seconds:     true,
+			spec:        "@every 1m",
+			next:        base.Add(time.Minute),
+			valid:       true,
+		},
+		{
+			description: "daily descriptor",
+			spec:        "@daily",
+			next:        base.Add(12 * time.Hour),
+			valid:       true,
+		},
+		{
+			description: "daily descriptor with seconds",
+			seconds:     true,
+			spec:        "@daily",
+			next:        base.Add(12 * time.Hour),
+			valid:       true,
+		},
+		{
+			description: "five fields with seconds",
+			seconds:     true,
+			spec:        "*/5 * * * *",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			s := New()
+			if tc.seconds {
+				s.WithSeconds()
+			}
+
+			schedule, err := s.parser().Parse(tc.spec)
+			if !tc.valid {
+				if err == nil {
+					t.Fatalf("expected %q to be invalid", tc.spec)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("could not parse %q: %v", tc.spec, err)
+			}
+
+			if next := schedule.Next(base); !next.Equal(tc.next) {
+				t.Fatalf("expected next run at %s, got %s", tc.next, next)
+			}
+		})
+	}
+}
+
+func TestPanickingHooks(t *testing.T) {
+	runs := 0
+
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		WithBeforeJob(func(string) { panic("before") }).
+		WithAfterJob(func(string, time.Duration, error) { panic("after") }).
+		AddJob("@yearly", "job", func() { runs++ })
+
+	for i := 0; i < 2; i++ {
+		if err := s.RunOnce(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if running := s.RunningJobs(); len(running) != 0 {
+			t.Fatalf("job still marked as running: %v", running)
+		}
+	}
+
+	if runs != 2 {
+		t.Fatalf("expected 2 runs, got %d", runs)
+	}
+}
+
+func TestPanickingJobReleasesLock(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	var keyDuringRun bool
+
+	s.AddJob("@yearly", "job", func() {
+		keyDuringRun = mr.Exists(s.keys.status("job"))
+		panic("boom")
+	})
+
+	if err := s.RunOnce(); err == nil {
+		t.Fatal("expected an error from the panicking job")
+	}
+
+	if !keyDuringRun {
+		t.Fatal("job key not written while running")
+	}
+
+	if mr.Exists(s.keys.status("job")) {
+		t.Fatal("job key not removed after panic")
+	}
+}
+
+// grantLocker is a Locker that grants every lock, even if it's already held.
+type grantLocker struct{}
+
+func (grantLocker) Acquire(string) (bool, func(), error) {
+	return true, func() {}, nil
+}
+
+// startSlowRun will start the first job of the schedule in a new goroutine and
+// return when the job is running. The job returns when the returned function is
+// called and the second function waits for the run to finish.
+func startSlowRun(t *testing.T, s *Schedule, locker Locker, job *Job, runs *int32) (func(), func()) {
+	t.Helper()
+
+	var (
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+		done    = make(chan struct{})
+		first   int32
+	)
+
+	job.run = func(context.Context) error {
+		atomic.AddInt32(runs, 1)
+
+		if atomic.CompareAndSwapInt32(&first, 0, 1) {
+			close(started)
+			<-unblock
+		}
+
+		return nil
+	}
+
+	go func() {
+		defer close(done)
+		s.lock(context.Background(), locker, job)()
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job never started")
+	}
+
+	return func() { close(unblock) }, func() { <-done }
+}
+
+func TestOverlap(t *testing.T) {
+	cases := []struct {
+		description  string
+		allowOverlap bool
+		expectedRuns int32
+	}{
+		{
+			description:  "skip if running by default",
+			expectedRuns: 1,
+		},
+		{
+			description:  "allow overlap",
+			allowOverlap: true,
+			expectedRuns: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			s := New().AddJob("* * * * *", "slow", func() {})
+			if tc.allowOverlap {
+				s.WithAllowOverlap()
+			}
+
+			var runs int32
+
+			unblock, wait := startSlowRun(t, s, grantLocker{}, s.jobs[0], &runs)
+
+			// Trigger the job again while the first run is still running.
+			s.lock(context.Background(), grantLocker{}, s.jobs[0])()
+
+			unblock()
+			wait()
+
+			if got := atomic.LoadInt32(&runs); got != tc.expectedRuns {
+				t.Fatalf("expected %d runs, got %d", tc.expectedRuns, got)
+			}
+		})
+	}
+}
+
+func TestValidateDuplicateNames(t *testing.T) {
+	s := New().WithLocker(NewInMemoryLocker())
+
+	// Add the jobs directly since addJob already rejects duplicate names.
+	s.jobs = []*Job{
+		{Name: "job", Spec: "* * * * *"},
+		{Name: "job", schedule: &onceSchedule{at: time.Now()}},
+		{Name: "interval", schedule: cron.Every(time.Second), interval: time.Second},
+		{Name: "interval", schedule: cron.Every(time.Second), interval: time.Second},
+	}
+
+	err := s.Validate()
+	if err == nil {
+		t.Fatal("expected duplicate names to be reported")
+	}
+
+	for _, name := range []string{"job", "interval"} {
+		if !strings.Contains(err.Error(), "duplicate job name "+name) {
+			t.Fatalf("duplicate %s not reported: %v", name, err)
+		}
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		AddJob("@yearly", "job", func() {})
+
+	stop := startSchedule(t, s)
+
+	if err := s.Run(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning from Run, got %v", err)
+	}
+
+	if err := s.RunContext(context.Background()); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning from RunContext, got %v", err)
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A stopped schedule can be started again.
+	if err := startSchedule(t, s)(); err != nil {
+		t.Fatalf("unexpected error when restarting: %v", err)
+	}
+}
+
+func TestRunFailureLeavesNothingBehind(t *testing.T) {
+	cases := []struct {
+		description string
+		setup       func(s *Schedule) *cron.Cron
+		expected    error
+	}{
+		{
+			description: "invalid spec",
+			setup: func(s *Schedule) *cron.Cron {
+				s.AddJob("not a spec", "invalid", func() {})
+				return nil
+			},
+		},
+		{
+			description: "spec rejected by the cron instance",
+			setup: func(s *Schedule) *cron.Cron {
+				// The spec is valid with seconds but the cron instance
+				// doesn't use seconds so it fails when added to cron,
+				// after the jobs before it.
+				c := cron.New()
+
+				s.WithSeconds().WithCron(c).AddJob("0 0 * * * *", "invalid", func() {})
+
+				return c
+			},
+		},
+		{
+			description: "redis unreachable",
+			setup: func(s *Schedule) *cron.Cron {
+				c := cron.New()
+
+				// Nothing is listening on port 1.
+				s.WithRedisPort(1).WithCron(c)
+
+				return c
+			},
+			expected: ErrRedisUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			mr, s := newTestSchedule(t)
+			defer mr.Close()
+
+			s.AddJob("@hourly", "first", func() {}).AddJob("@daily", "second", func() {})
+
+			c := tc.setup(s)
+
+			s.AddJob("@weekly", "last", func() {})
+
+			goroutines := runtime.NumGoroutine()
+
+			expected := tc.expected
+			if expected == nil {
+				expected = ErrInvalidSpec
+			}
+
+			if err := s.RunContext(context.Background()); !errors.Is(err, expected) {
+				t.Fatalf("expected %v, got %v", expected, err)
+			}
+
+			if entries := s.Entries(); len(entries) != 0 {
+				t.Fatalf("expected no entries, got %d", len(entries))
+			}
+
+			if c != nil && len(c.Entries()) != 0 {
+				t.Fatalf("expected no entries left in cron, got %d", len(c.Entries()))
+			}
+
+			s.mu.Lock()
+			pool, running := s.pool, s.stop != nil
+			s.mu.Unlock()
+
+			if pool != nil || running {
+				t.Fatal("expected the pool to be closed and the schedule stopped")
+			}
+
+			if got := runtime.NumGoroutine(); got > goroutines {
+				t.Fatalf("expected at most %d goroutines, got %d", goroutines, got)
+			}
+		})
+	}
+}
+
+func TestZeroValueSchedule(t *testing.T) {
+	if err := (&Schedule{}).Run(); err == nil {
+		t.Fatal("expected an error without any jobs")
+	}
+
+	s := (&Schedule{}).
+		WithLogger(nil).
+		WithLocker(NewInMemoryLocker()).
+		AddJob("@yearly", "job", func() {})
+
+	if err := s.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.RunContext(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	var (
+		keyDuringRun bool
+		ctxErr       error
+	)
+
+	s.AddJobCtx("@yearly", "job", func(ctx context.Context) error {
+		keyDuringRun = mr.Exists(s.keys.status("job"))
+
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+
+		return nil
+	}, WithTimeout(50*time.Millisecond))
+
+	if err := s.RunOnce(); !errors.Is(err, ErrJobTimeout) {
+		t.Fatalf("expected ErrJobTimeout, got %v", err)
+	}
+
+	if !errors.Is(ctxErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the context to be cancelled by the timeout, got %v", ctxErr)
+	}
+
+	if !keyDuringRun {
+		t.Fatal("job key not written while running")
+	}
+
+	if mr.Exists(s.keys.status("job")) {
+		t.Fatal("job key not removed after timeout")
+	}
+}
+
+func TestTeardownRemovesLiveJobs(t *testing.T) {
+	c := cron.New()
+
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		WithCron(c).
+		AddJob("@yearly", "job", func() {})
+
+	stop := startSchedule(t, s)
+
+	if _, err := s.AddJobLive("@yearly", "live", func() {}); err != nil {
+		t.Fatalf("could not add live job: %v", err)
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if entries := c.Entries(); len(entries) != 0 {
+		t.Fatalf("expected no entries after teardown, got %d", len(entries))
+	}
+
+	// The live job is scheduled again together with the other job.
+	stop = startSchedule(t, s)
+
+	if entries := c.Entries(); len(entries) != 2 {
+		t.Fatalf("expected 2 entries after restart, got %d", len(entries))
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTokenToEntryID(t *testing.T) {
+	s := New().WithLocker(NewInMemoryLocker())
+	token := s.AddJobToken("@yearly", "job", func() {})
+
+	stop := startSchedule(t, s)
+	defer stop()
+
+	liveID, err := s.AddJobLive("@yearly", "live", func() {})
+	if err != nil {
+		t.Fatalf("could not add live job: %v", err)
+	}
+
+	if _, ok := s.TokenToEntryID(0); ok {
+		t.Fatal("zero token matched a job")
+	}
+
+	id, ok := s.TokenToEntryID(token)
+	if !ok || id == liveID {
+		t.Fatalf("expected the entry of the job, got %d %t", id, ok)
+	}
+
+	s.mu.Lock()
+	liveToken := s.jobs[len(s.jobs)-1].token
+	s.mu.Unlock()
+
+	if liveToken == 0 || liveToken == token {
+		t.Fatalf("expected a new token for the live job, got %d", liveToken)
+	}
+
+	if id, ok := s.TokenToEntryID(liveToken); !ok || id != liveID {
+		t.Fatalf("expected entry %d for the live job, got %d %t", liveID, id, ok)
+	}
+}
@@ -0,0 +1,69 @@
+package distcron
+
+// Drain will stop triggering any new jobs but keep the schedule running and the
+// connection to Redis open, and block until all jobs already triggered have
+// finished. This can be used to let other processes take over all jobs before
+// the process is stopped, e.g. during a rolling deploy. Use Resume to start
+// triggering jobs again.
+//
+// A schedule is running when started with Run or RunContext. Drain moves it
+// from running to drained and Resume moves it back. A running or drained
+// schedule is stopped when the context passed to RunContext is cancelled, a
+// signal is caught or Stop is called. A stopped schedule can't be drained or
+// resumed and ErrNotRunning is returned. Calling Drain on a drained schedule is
+// a no-op.
+func (s *Schedule) Drain() error {
+	s.mu.Lock()
+
+	if s.cron == nil {
+		s.mu.Unlock()
+		return ErrNotRunning
+	}
+
+	c, clockRuns := s.cron, s.clockRuns
+	s.drained = true
+	s.mu.Unlock()
+
+	s.logger.Info("draining schedule", "node", s.nodeID)
+
+	<-c.Stop().Done()
+	clockRuns.Wait()
+
+	s.logger.Info("schedule drained", "node", s.nodeID)
+
+	return nil
+}
+
+// Resume will start triggering jobs again after Drain has been called.
+// ErrNotRunning is returned if the schedule is stopped and calling Resume on a
+// schedule that isn't drained is a no-op.
+func (s *Schedule) Resume() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cron == nil {
+		return ErrNotRunning
+	}
+
+	if !s.drained {
+		return nil
+	}
+
+	s.drained = false
+
+	if s.clock == nil {
+		s.cron.Start()
+	}
+
+	s.logger.Info("schedule resumed", "node", s.nodeID)
+
+	return nil
+}
+
+// IsDrained reports if the schedule is drained with Drain.
+func (s *Schedule) IsDrained() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.drained
+}
@@ -0,0 +1,99 @@
+package distcron
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// dryRunCount is the number of upcoming runs logged for each job in dry run
+// mode.
+const dryRunCount = 5
+
+// WithDryRun will make Run and RunContext validate the schedule and log the
+// next five times each job would run, in the order the jobs were added, and
+// then return without connecting to Redis or running any job. This can be used
+// to preview a schedule before deploying it.
+func (s *Schedule) WithDryRun() *Schedule {
+	s.dryRun = true
+	return s
+}
+
+// logDryRun will log the upcoming runs for every job.
+func (s *Schedule) logDryRun() {
+	s.mu.Lock()
+	jobs := append([]*Job{}, s.jobs...)
+	s.mu.Unlock()
+
+	now := s.now().In(s.location)
+
+	for _, job := range jobs {
+		next := []string{}
+
+		for _, t := range s.upcomingRuns(job, now, dryRunCount) {
+			next = append(next, t.Format(time.RFC3339))
+		}
+
+		s.logger.Info("dry run", "job", job.Name, "spec", job.Spec, "next", next)
+	}
+}
+
+// upcomingRuns returns up to n times after now that the job will run. Jobs
+// added with AddOnceAt only have a single run.
+func (s *Schedule) upcomingRuns(job *Job, now time.Time, n int) []time.Time {
+	// The once schedule keeps returning a time until it's triggered so only
+	// check it once.
+	if once, ok := job.schedule.(*onceSchedule); ok {
+		if next := once.Next(now); !next.IsZero() {
+			return []time.Time{next}
+		}
+
+		return nil
+	}
+
+	// Jobs added with AddInterval are checked often but only run when the
+	// gap has passed, assume that every run finishes right away.
+	if job.interval > 0 {
+		s.mu.Lock()
+		finished := s.intervalFinished[job.Name]
+		s.mu.Unlock()
+
+		runs := make([]time.Time, 0, n)
+
+		for next := job.schedule.Next(now); len(runs) < n; {
+			run := nextIntervalRun(job, finished, next)
+			runs = append(runs, run)
+
+			finished, next = run, job.schedule.Next(run)
+		}
+
+		return runs
+	}
+
+	schedule := job.schedule
+	if schedule == nil {
+		var err error
+
+		// The spec is validated before this is called.
+		if schedule, err = s.parser().Parse(job.Spec); err != nil {
+			return nil
+		}
+	}
+
+	return nextTimes(schedule, now, n)
+}
+
+// nextTimes returns the next n times of the schedule after now.
+func nextTimes(schedule cron.Schedule, now time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+
+	for t := now; len(times) < n; {
+		if t = schedule.Next(t); t.IsZero() {
+			break
+		}
+
+		times = append(times, t)
+	}
+
+	return times
+}
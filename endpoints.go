@@ -0,0 +1,43 @@
+package distcron
+
+import "crypto/tls"
+
+// RedisEndpoint is one independent Redis instance used with
+// WithRedisEndpoints.
+type RedisEndpoint struct {
+	Host      string
+	Port      int
+	DB        int
+	Username  string
+	Password  string
+	TLSConfig *tls.Config
+}
+
+// WithRedisEndpoints will use multiple independent Redis instances for the
+// redsync mutexes to get the quorum guarantees of the Redlock algorithm. A
+// mutex is only acquired if it could be locked in a majority of the instances
+// so an odd number of endpoints should be used. The first endpoint is the
+// primary which replaces the host, port, database, credentials and TLS
+// configuration set with other options. The primary is the only endpoint used
+// for everything but the mutexes, such as the job keys, the last runs and load
+// balancing. Options taking precedence over the host and port, such as
+// WithRedisURL, also take precedence over the primary endpoint.
+func (s *Schedule) WithRedisEndpoints(endpoints []RedisEndpoint) *Schedule {
+	if len(endpoints) == 0 {
+		return s
+	}
+
+	primary := endpoints[0]
+
+	s.redisHost = primary.Host
+	s.redisPort = primary.Port
+	s.redisDB = primary.DB
+	s.redisUsername = primary.Username
+	s.redisPassword = primary.Password
+	s.tlsConfig = primary.TLSConfig
+
+	s.quorumEndpoints = endpoints[1:]
+	s.quorumPools = nil
+
+	return s
+}
@@ -0,0 +1,103 @@
+package distcron
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisEndpointsQuorum(t *testing.T) {
+	cases := []struct {
+		description string
+		held        int
+		closed      int
+		expectLock  bool
+	}{
+		{
+			description: "all endpoints available",
+			expectLock:  true,
+		},
+		{
+			description: "mutex held in a minority",
+			held:        1,
+			expectLock:  true,
+		},
+		{
+			description: "one endpoint down",
+			closed:      1,
+			expectLock:  true,
+		},
+		{
+			description: "mutex held in a majority",
+			held:        2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			servers := make([]*miniredis.Miniredis, 3)
+			endpoints := make([]RedisEndpoint, 3)
+
+			for i := range servers {
+				mr, err := miniredis.Run()
+				if err != nil {
+					t.Fatalf("could not start redis: %v", err)
+				}
+
+				defer mr.Close()
+
+				port, _ := strconv.Atoi(mr.Port())
+
+				servers[i] = mr
+				endpoints[i] = RedisEndpoint{Host: mr.Host(), Port: port}
+			}
+
+			s := New().
+				WithNodeID("node-1").
+				WithLockOptions(0, 1, 0).
+				WithRedisEndpoints(endpoints)
+
+			// Hold the mutex in the last endpoints as another process
+			// would and stop the ones after the primary.
+			for i := 0; i < tc.held; i++ {
+				if err := servers[len(servers)-1-i].Set(s.keys.mutex("job"), "other"); err != nil {
+					t.Fatalf("could not set mutex: %v", err)
+				}
+			}
+
+			for i := 0; i < tc.closed; i++ {
+				servers[1+i].Close()
+			}
+
+			ok, release, err := newTestLocker(s).Acquire("job")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if ok != tc.expectLock {
+				t.Fatalf("expected lock to be acquired: %t, got %t", tc.expectLock, ok)
+			}
+
+			if !ok {
+				if servers[0].Exists(s.keys.status("job")) {
+					t.Fatal("job key written without the mutex")
+				}
+
+				return
+			}
+
+			if !servers[0].Exists(s.keys.status("job")) {
+				t.Fatal("job key not written to the primary")
+			}
+
+			release()
+
+			if servers[0].Exists(s.keys.status("job")) {
+				t.Fatal("job key not removed")
+			}
+		})
+	}
+}
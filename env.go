@@ -0,0 +1,74 @@
+package distcron
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	EnvRedisURL      = "DISTCRON_REDIS_URL"
+	EnvRedisHost     = "DISTCRON_REDIS_HOST"
+	EnvRedisPort     = "DISTCRON_REDIS_PORT"
+	EnvRedisDB       = "DISTCRON_REDIS_DB"
+	EnvRedisUsername = "DISTCRON_REDIS_USERNAME"
+	EnvRedisPassword = "DISTCRON_REDIS_PASSWORD"
+)
+
+// NewFromEnv creates a new Schedule with the Redis connection configured from
+// environment variables. Variables that aren't set will use the defaults from
+// New. If DISTCRON_REDIS_URL is set it will take precedence over the other
+// variables, just like WithRedisURL. An error is returned if a variable has an
+// invalid value.
+func NewFromEnv() (*Schedule, error) {
+	s := New()
+
+	if host, ok := os.LookupEnv(EnvRedisHost); ok {
+		s.WithRedisHost(host)
+	}
+
+	if port, ok, err := lookupEnvInt(EnvRedisPort); err != nil {
+		return nil, err
+	} else if ok {
+		s.WithRedisPort(port)
+	}
+
+	if db, ok, err := lookupEnvInt(EnvRedisDB); err != nil {
+		return nil, err
+	} else if ok {
+		s.WithRedisDB(db)
+	}
+
+	if username, ok := os.LookupEnv(EnvRedisUsername); ok {
+		s.WithRedisUsername(username)
+	}
+
+	if password, ok := os.LookupEnv(EnvRedisPassword); ok {
+		s.WithRedisPassword(password)
+	}
+
+	if rawURL, ok := os.LookupEnv(EnvRedisURL); ok {
+		if err := s.WithRedisURL(rawURL); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", EnvRedisURL, err)
+		}
+	}
+
+	return s, nil
+}
+
+// lookupEnvInt will look up the environment variable and parse it as an
+// integer. The returned bool reports whether the variable was set.
+func lookupEnvInt(key string) (int, bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value %q for %s: must be an integer", value, key)
+	}
+
+	return i, true, nil
+}
@@ -13,7 +13,7 @@ import (
 func main() {
 	dc := distcron.New().
 		WithLogger(cron.VerbosePrintfLogger(log.New(os.Stdout, "cron: ", log.LstdFlags))).
-		AddJob("* * * * * ", "my-job", myJob)
+		AddJob("* * * * *", "my-job", myJob)
 
 	if err := dc.Run(); err != nil {
 		panic(err)
@@ -0,0 +1,68 @@
+package distcron
+
+import (
+	"sync"
+	"time"
+)
+
+// Extender can be implemented by a Locker to extend the expiry of a lock held
+// by a running job. The default Redis locker implements this interface by
+// resetting the TTL of the job key.
+type Extender interface {
+	// Extend will extend the lock for the job with the given name. False is
+	// returned if the lock is no longer held.
+	Extend(name string) (bool, error)
+}
+
+// WithLockExtension will periodically extend the lock for a running job with
+// the given interval. This makes it possible to use a short TTL with WithJobTTL
+// to recover quickly from crashed processes while still supporting jobs running
+// longer than the TTL. The interval should be shorter than the TTL. Locks are
+// not extended by default.
+func (s *Schedule) WithLockExtension(interval time.Duration) *Schedule {
+	s.lockExtension = interval
+	return s
+}
+
+// extendLock will start extending the lock for the job in the background if
+// lock extension is configured and the locker supports it. The returned
+// function stops the extension and must always be called.
+func (s *Schedule) extendLock(locker Locker, name string) func() {
+	extender, ok := locker.(Extender)
+	if !ok || s.lockExtension <= 0 {
+		return func() {}
+	}
+
+	var (
+		done = make(chan struct{})
+		once sync.Once
+	)
+
+	go func() {
+		ticker := time.NewTicker(s.lockExtension)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			ok, err := extender.Extend(name)
+			if err != nil {
+				s.logger.Error(err, "could not extend lock", s.jobFields(name, "error")...)
+				continue
+			}
+
+			if !ok {
+				s.logger.Info("lock no longer held, stopping extension", s.jobFields(name, "lost")...)
+				return
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,91 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// fairnessMaxDelay is the longest delay added before trying to take the
+	// lock when using WithFairness. This bounds the head start any process can
+	// get so no process is starved.
+	fairnessMaxDelay = 500 * time.Millisecond
+
+	// fairnessWindow is the time since the last win after which a process
+	// gets no delay at all.
+	fairnessWindow = 10 * time.Minute
+)
+
+// WithFairness will give processes that haven't run any job recently a head
+// start when racing for the lock. Every process records the last time it won a
+// lock in Redis and before trying to take a lock it waits up to 500
+// milliseconds, shorter the longer ago it last won. A process that hasn't won
+// for 10 minutes doesn't wait at all. This spreads the jobs more evenly over the
+// processes without assigning them like WithLoadBalancing. The times are
+// recorded with Redis even if a custom Locker is used.
+func (s *Schedule) WithFairness() *Schedule {
+	s.fairness = true
+	return s
+}
+
+// fairnessDelay returns the time to wait before trying to take the lock based
+// on the last time this process won a lock.
+func (s *Schedule) fairnessDelay(name string) time.Duration {
+	lastWin, err := redis.Int64(do(s.redisPool(), "HGET", s.keys.wins(), s.nodeID))
+	if errors.Is(err, redis.ErrNil) {
+		return 0
+	}
+
+	if err != nil {
+		s.logger.Error(err, "could not get last win", s.jobFields(name, "error")...)
+		return 0
+	}
+
+	idle := s.now().Sub(time.Unix(0, lastWin))
+	if idle >= fairnessWindow {
+		return 0
+	}
+
+	if idle < 0 {
+		idle = 0
+	}
+
+	return time.Duration(float64(fairnessMaxDelay) * (1 - float64(idle)/float64(fairnessWindow)))
+}
+
+// sleepFairness will wait for the fairness delay if fairness is enabled. False
+// is returned if the context was cancelled while waiting.
+func (s *Schedule) sleepFairness(ctx context.Context, name string) bool {
+	if !s.fairness {
+		return true
+	}
+
+	d := s.fairnessDelay(name)
+	if d == 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordWin will record that this process won a lock if fairness is enabled.
+func (s *Schedule) recordWin(name string) {
+	if !s.fairness {
+		return
+	}
+
+	if _, err := do(s.redisPool(), "HSET", s.keys.wins(), s.nodeID, s.now().UnixNano()); err != nil {
+		s.logger.Error(err, "could not record win", s.jobFields(name, "error")...)
+	}
+}
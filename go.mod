@@ -3,8 +3,10 @@ module github.com/bombsimon/distcron
 go 1.13
 
 require (
+	github.com/alicebob/miniredis/v2 v2.11.4
 	github.com/go-redsync/redsync v1.4.1
 	github.com/gomodule/redigo v2.0.0+incompatible
-	github.com/hashicorp/go-multierror v1.1.0 // indirect
+	github.com/hashicorp/go-multierror v1.1.0
 	github.com/robfig/cron/v3 v3.0.1
+	gopkg.in/yaml.v2 v2.2.8
 )
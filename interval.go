@@ -0,0 +1,157 @@
+package distcron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// intervalMinPoll and intervalMaxPoll bound how often a job added with
+	// AddInterval is checked. The job is checked ten times per gap within
+	// these bounds so it runs at most a tenth of the gap late.
+	intervalMinPoll = time.Second
+	intervalMaxPoll = time.Minute
+)
+
+// AddInterval will add a job that runs gap after the previous run finished, in
+// any process, instead of at fixed times. This means that runs never overlap no
+// matter how long they take, unlike "@every" which runs at a fixed rate. The
+// time the job last finished is stored in Redis and the job is checked ten
+// times per gap, but at least every minute and at most every second, so it runs
+// slightly later than the gap. A job that has never finished runs the first
+// time it's checked, which is one check interval after the schedule is started.
+// Use WithRunOnStart to run it when the schedule is started instead. The job
+// still takes the lock as any other job. When using a custom Locker the time is
+// only tracked in this process.
+//
+// The spec of the job is set to "@after" followed by the gap, e.g. "@after 5m",
+// which is only used to describe the job. NextRuns, Summary and WithDryRun return
+// the first check after the gap has passed since the job last finished in this
+// process, not every check.
+func (s *Schedule) AddInterval(gap time.Duration, name string, f func(), opts ...JobOption) *Schedule {
+	if gap <= 0 {
+		s.mu.Lock()
+		s.errs = append(s.errs, fmt.Errorf("invalid interval %s for job %s", gap, name))
+		s.mu.Unlock()
+
+		return s
+	}
+
+	poll := gap / 10
+	if poll < intervalMinPoll {
+		poll = intervalMinPoll
+	} else if poll > intervalMaxPoll {
+		poll = intervalMaxPoll
+	}
+
+	return s.addJob(&Job{
+		Spec:     "@after " + gap.String(),
+		Name:     name,
+		Func:     f,
+		run:      withContext(f),
+		schedule: cron.Every(poll),
+		interval: gap,
+	}, opts...)
+}
+
+// nextRun returns the next time the job will run when cron triggers it next.
+// For jobs added with AddInterval this is the first check when the job is due.
+// This must be called while holding the lock.
+func (s *Schedule) nextRun(job *Job, next time.Time) time.Time {
+	if job.interval <= 0 || next.IsZero() {
+		return next
+	}
+
+	return nextIntervalRun(job, s.intervalFinished[job.Name], next)
+}
+
+// nextIntervalRun returns when the job added with AddInterval will run if it
+// finished at the given time and is checked next at next. A job that has never
+// finished runs the first time it's checked.
+func nextIntervalRun(job *Job, finished, next time.Time) time.Time {
+	every, ok := job.schedule.(cron.ConstantDelaySchedule)
+
+	due := finished.Add(job.interval)
+	if !ok || finished.IsZero() || !due.After(next) {
+		return next
+	}
+
+	checks := (due.Sub(next) + every.Delay - 1) / every.Delay
+
+	return next.Add(time.Duration(checks) * every.Delay)
+}
+
+// intervalDue reports if the job added with AddInterval is due to run, that is
+// if it's not running in any process and gap has passed since it last finished.
+func (s *Schedule) intervalDue(job *Job) bool {
+	if s.isRunning(job.Name) {
+		return false
+	}
+
+	s.mu.Lock()
+	finished := s.intervalFinished[job.Name]
+	s.mu.Unlock()
+
+	if s.locker == nil {
+		lockName := job.lockName()
+		k := s.jobKeys(lockName)
+
+		values, err := redis.Values(do(s.redisPool(), "MGET", k.finished(job.Name), k.status(lockName)))
+		if err != nil || len(values) != 2 {
+			if err == nil {
+				err = errors.New("unexpected reply from MGET")
+			}
+
+			s.logger.Error(err, "could not get last finished time", s.jobFields(job.Name, "error")...)
+
+			return false
+		}
+
+		// The job is running in another process.
+		if values[1] != nil {
+			return false
+		}
+
+		if values[0] != nil {
+			nanos, err := redis.Int64(values[0], nil)
+			if err != nil {
+				s.logger.Error(err, "invalid last finished time", s.jobFields(job.Name, "error")...)
+				return false
+			}
+
+			if t := time.Unix(0, nanos); t.After(finished) {
+				finished = t
+			}
+		}
+	}
+
+	return !s.now().Before(finished.Add(job.interval))
+}
+
+// recordFinished will store the time the job added with AddInterval finished.
+func (s *Schedule) recordFinished(job *Job) {
+	now := s.now()
+
+	s.mu.Lock()
+	if s.intervalFinished == nil {
+		s.intervalFinished = map[string]time.Time{}
+	}
+
+	s.intervalFinished[job.Name] = now
+	s.mu.Unlock()
+
+	if s.locker != nil {
+		return
+	}
+
+	key := s.jobKeys(job.Name).finished(job.Name)
+
+	if _, err := do(s.redisPool(), "SET", key, strconv.FormatInt(now.UnixNano(), 10)); err != nil {
+		s.logger.Error(err, "could not store last finished time", s.jobFields(job.Name, "error")...)
+	}
+}
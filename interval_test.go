@@ -0,0 +1,55 @@
+package distcron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalUpcomingRuns(t *testing.T) {
+	s := New().AddInterval(5*time.Minute, "job", func() {})
+
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	runs := s.upcomingRuns(s.jobs[0], now, 3)
+
+	// The job is checked every 30 seconds and runs the first time.
+	expected := []time.Time{
+		now.Add(30 * time.Second),
+		now.Add(5*time.Minute + 30*time.Second),
+		now.Add(10*time.Minute + 30*time.Second),
+	}
+
+	if len(runs) != len(expected) {
+		t.Fatalf("expected %d runs, got %v", len(expected), runs)
+	}
+
+	for i := range expected {
+		if !runs[i].Equal(expected[i]) {
+			t.Fatalf("expected run %d at %s, got %s", i, expected[i], runs[i])
+		}
+	}
+}
+
+func TestIntervalNextRuns(t *testing.T) {
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		AddInterval(5*time.Minute, "job", func() {})
+
+	stop := startSchedule(t, s)
+	defer stop()
+
+	finished := time.Now()
+
+	s.mu.Lock()
+	s.intervalFinished = map[string]time.Time{"job": finished}
+	s.mu.Unlock()
+
+	next := s.NextRuns()["job"]
+	if next.Before(finished.Add(5*time.Minute)) || next.After(finished.Add(5*time.Minute+30*time.Second)) {
+		t.Fatalf("expected next run at the first check after the gap, got %s", next)
+	}
+
+	summary := s.Summary()
+	if len(summary.Jobs) != 1 || summary.Jobs[0].NextRun == nil || !summary.Jobs[0].NextRun.Equal(next) {
+		t.Fatalf("expected the summary to report %s, got %+v", next, summary.Jobs)
+	}
+}
@@ -0,0 +1,81 @@
+package distcron
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// jitter is used to add a random delay before trying to acquire the lock for a
+// job.
+type jitter struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+	max  time.Duration
+}
+
+func newJitter(max time.Duration, seed int64) *jitter {
+	return &jitter{
+		rand: rand.New(rand.NewSource(seed)), // nolint: gosec
+		max:  max,
+	}
+}
+
+// duration returns a random duration between 0 and max.
+func (j *jitter) duration() time.Duration {
+	if j == nil || j.max <= 0 {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return time.Duration(j.rand.Int63n(int64(j.max) + 1))
+}
+
+// WithJitter will sleep a random duration between 0 and max before trying to
+// acquire the lock for a job. This spreads out the load on Redis when many
+// processes and jobs are scheduled at the same time. The schedule itself is
+// not changed. No jitter is added by default.
+func (s *Schedule) WithJitter(max time.Duration) *Schedule {
+	seed := time.Now().UnixNano()
+	if s.jitterSeed != nil {
+		seed = *s.jitterSeed
+	}
+
+	s.jitter = newJitter(max, seed)
+
+	return s
+}
+
+// WithJitterSeed sets the seed used to randomize the jitter set with
+// WithJitter. This is useful to get a deterministic jitter in tests.
+func (s *Schedule) WithJitterSeed(seed int64) *Schedule {
+	s.jitterSeed = &seed
+
+	if s.jitter != nil {
+		s.jitter = newJitter(s.jitter.max, seed)
+	}
+
+	return s
+}
+
+// sleepJitter will sleep for a random duration if jitter is configured. False
+// is returned if the context was cancelled while sleeping.
+func (s *Schedule) sleepJitter(ctx context.Context) bool {
+	d := s.jitter.duration()
+	if d == 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
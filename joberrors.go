@@ -0,0 +1,71 @@
+package distcron
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// JobError is an error returned from a job. It's used for the errors returned
+// when using WithCollectErrors and from RunOnce.
+type JobError struct {
+	Name string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *JobError) Error() string {
+	return fmt.Sprintf("job %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the error returned from the job.
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// WithCollectErrors will collect every error returned from a job, after any
+// retries, while the schedule is running. When the schedule is stopped Run and
+// RunContext return all of them as a *multierror.Error holding one *JobError for
+// each failed run. This is meant for a finite set of jobs, e.g. from a CLI,
+// where the caller wants to know what failed. A long running schedule should not
+// use this since the errors are kept in memory until the schedule stops. By
+// default errors from jobs are only logged.
+func (s *Schedule) WithCollectErrors() *Schedule {
+	s.collectErrors = true
+	return s
+}
+
+// collectError will store the error returned from the job if errors are
+// collected or if the job is started by RunOnce.
+func (s *Schedule) collectError(ctx context.Context, name string, err error) {
+	if state, ok := ctx.Value(runOnceKey{}).(*runOnceState); ok {
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		state.errs = multierror.Append(state.errs, &JobError{Name: name, Err: err})
+
+		return
+	}
+
+	if !s.collectErrors {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobErrs = multierror.Append(s.jobErrs, &JobError{Name: name, Err: err})
+}
+
+// takeErrors returns the errors collected since the last call and resets them.
+// A nil error is returned if no job failed.
+func (s *Schedule) takeErrors() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := s.jobErrs
+	s.jobErrs = nil
+
+	return result.ErrorOrNil()
+}
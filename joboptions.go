@@ -0,0 +1,86 @@
+package distcron
+
+import "time"
+
+// JobOption configures a single job when it's added to the schedule.
+type JobOption func(*Job)
+
+// WithRunOnStart will run the job once when the schedule is started in
+// addition to its schedule. The run still requires the lock so only one
+// process will run it. If the job is scheduled to run within a second from the
+// start anyway the extra run is skipped to not run the job twice.
+func WithRunOnStart() JobOption {
+	return func(j *Job) {
+		j.runOnStart = true
+	}
+}
+
+// WithRetry will retry the job up to the given number of attempts if it returns
+// an error or panics. The time to wait between each attempt starts at backoff
+// and is doubled for every attempt. The lock is held during all attempts and
+// extended before each one if the locker supports it so no other process will
+// start the job in between. Only the final error is reported to WithAfterJob,
+// WithOnFailure and the metrics.
+func WithRetry(attempts int, backoff time.Duration) JobOption {
+	return func(j *Job) {
+		j.retries = attempts
+		j.retryBackoff = backoff
+	}
+}
+
+// WithMaxConcurrent will allow up to max processes to run the job at the same
+// time instead of only one. When all slots are taken the job is skipped. This
+// requires a Locker implementing Semaphore, other lockers will only allow one
+// process to run the job. Since every run has its own slot, locks for jobs using
+// this option are not extended with WithLockExtension.
+func WithMaxConcurrent(max int) JobOption {
+	return func(j *Job) {
+		j.maxConcurrent = max
+	}
+}
+
+// WithLockGroup will make the job share its lock with every other job in the
+// same group so no two jobs in the group run at the same time in any process.
+// Jobs without a group use their own name as the lock, which means that a
+// group with the same name as a job will share the lock with that job.
+func WithLockGroup(name string) JobOption {
+	return func(j *Job) {
+		j.lockGroup = name
+	}
+}
+
+// WithTags adds the tags to the job. Tags can be used to organize large
+// schedules and to find jobs with JobsWithTag.
+func WithTags(tags map[string]string) JobOption {
+	return func(j *Job) {
+		if j.Tags == nil {
+			j.Tags = map[string]string{}
+		}
+
+		for k, v := range tags {
+			j.Tags[k] = v
+		}
+	}
+}
+
+// WithJobKeyPrefix sets the prefix used for the keys written to Redis for the
+// job instead of the one set with WithKeyPrefix. This makes it possible to keep
+// the locks of jobs belonging to different tenants apart in the same Redis
+// database. Jobs sharing a lock group with WithLockGroup must use the same
+// prefix. An empty prefix uses the prefix of the schedule.
+func WithJobKeyPrefix(prefix string) JobOption {
+	return func(j *Job) {
+		j.keyPrefix = prefix
+	}
+}
+
+// WithTimeout will cancel the context passed to the job when it has run for the
+// given duration, including any retries. The run is reported as failed with
+// ErrJobTimeout and the lock is no longer extended with WithLockExtension so it
+// expires even if the job doesn't respect the context. The lock is released
+// when the job returns. Only jobs added with AddJobCtx can see the context.
+func WithTimeout(d time.Duration) JobOption {
+	return func(j *Job) {
+		j.timeout = d
+	}
+}
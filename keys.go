@@ -0,0 +1,136 @@
+package distcron
+
+// keys is used to build the name of every key written to Redis.
+type keys struct {
+	prefix string
+
+	// hashTags is set when using Redis Cluster to store all keys for a job
+	// in the same hash slot.
+	hashTags bool
+
+	// namer is set with WithKeyNamer and replaces the names of the mutex and
+	// status key.
+	namer func(name string) (mutexKey, statusKey string)
+}
+
+// WithKeyPrefix sets a prefix used for every key written to Redis, e.g.
+// "myapp:distcron:". This makes it possible for multiple applications to share
+// the same Redis database without running into each others keys. The prefix
+// can be overridden for a single job with WithJobKeyPrefix. No prefix is used by
+// default.
+func (s *Schedule) WithKeyPrefix(prefix string) *Schedule {
+	s.keys.prefix = prefix
+	return s
+}
+
+// WithKeyNamer sets the function used to build the name of the redsync mutex and
+// the key written while a job is running from the name of the job, or the lock
+// group set with WithLockGroup. This gives full control over the layout of the
+// keys holding the locks, e.g. to share them with other systems. Neither the
+// prefix set with WithKeyPrefix or WithJobKeyPrefix nor the hash tags used with
+// Redis Cluster are added to the names. All other keys are named as usual.
+func (s *Schedule) WithKeyNamer(namer func(jobName string) (mutexKey, statusKey string)) *Schedule {
+	s.keys.namer = namer
+	return s
+}
+
+// jobKeys returns the keys for the job or lock group with the given name. Jobs
+// using WithJobKeyPrefix use their own prefix instead of the one set for the
+// schedule.
+func (s *Schedule) jobKeys(name string) keys {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := s.keys
+
+	for _, job := range s.jobs {
+		if (job.Name == name || job.lockName() == name) && job.keyPrefix != "" {
+			k.prefix = job.keyPrefix
+			break
+		}
+	}
+
+	return k
+}
+
+// mutex returns the name of the redsync mutex for the job.
+func (k keys) mutex(name string) string {
+	if k.namer != nil {
+		mutexKey, _ := k.namer(name)
+		return mutexKey
+	}
+
+	return k.prefix + "GLOBAL-" + k.tag(name)
+}
+
+// status returns the key written while the job is running.
+func (k keys) status(name string) string {
+	if k.namer != nil {
+		_, statusKey := k.namer(name)
+		return statusKey
+	}
+
+	return k.prefix + k.tag(name)
+}
+
+// done returns the key written when a job added with AddOnceAt has run.
+func (k keys) done(name string) string {
+	return k.prefix + "DONE-" + k.tag(name)
+}
+
+// lastRun returns the key holding the last run of the job.
+func (k keys) lastRun(name string) string {
+	return k.prefix + "distcron:lastrun:" + k.tag(name)
+}
+
+// leader returns the name of the redsync mutex used for leader election.
+func (k keys) leader() string {
+	return k.prefix + "LEADER"
+}
+
+// nodes returns the key holding the live nodes used for load balancing.
+func (k keys) nodes() string {
+	return k.prefix + "NODES"
+}
+
+// slots returns the key holding the slots for a job using WithMaxConcurrent.
+func (k keys) slots(name string) string {
+	return k.prefix + "SLOTS-" + k.tag(name)
+}
+
+// wins returns the key holding the last time each node won a lock when using
+// fairness.
+func (k keys) wins() string {
+	return k.prefix + "WINS"
+}
+
+// paused returns the key written when a job is paused with WithSharedPause.
+func (k keys) paused(name string) string {
+	return k.prefix + "PAUSED-" + k.tag(name)
+}
+
+// failures returns the key holding the consecutive failures of a job when
+// using WithCircuitBreaker.
+func (k keys) failures(name string) string {
+	return k.prefix + "FAILURES-" + k.tag(name)
+}
+
+// finished returns the key holding the time a job added with AddInterval last
+// finished.
+func (k keys) finished(name string) string {
+	return k.prefix + "FINISHED-" + k.tag(name)
+}
+
+// enabled returns the key holding the enabled flag read by ReloadEnabled.
+func (k keys) enabled(name string) string {
+	return k.prefix + "ENABLED-" + k.tag(name)
+}
+
+// tag returns the name of the job as a hash tag if hash tags are used.
+func (k keys) tag(name string) string {
+	if !k.hashTags {
+		return name
+	}
+
+	return "{" + name + "}"
+}
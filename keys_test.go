@@ -0,0 +1,52 @@
+package distcron
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJobKeyPrefix(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	port, _ := strconv.Atoi(mr.Port())
+
+	// The same job in two tenants using the same Redis database.
+	s.AddJob("@hourly", "job", func() {}, WithJobKeyPrefix("tenant-a:"))
+
+	other := New().
+		WithRedisHost(mr.Host()).
+		WithRedisPort(port).
+		WithNodeID("node-2").
+		AddJob("@hourly", "job", func() {}, WithJobKeyPrefix("tenant-b:"))
+
+	for _, schedule := range []*Schedule{s, other} {
+		if ok, _, err := newTestLocker(schedule).Acquire("job"); err != nil || !ok {
+			t.Fatalf("could not acquire lock: %v", err)
+		}
+	}
+
+	keys := mr.Keys()
+	if len(keys) != 2 {
+		t.Fatalf("expected one key for each tenant, got %v", keys)
+	}
+
+	for _, prefix := range []string{"tenant-a:", "tenant-b:"} {
+		found := false
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, prefix) {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatalf("no key with prefix %s in %v", prefix, keys)
+		}
+	}
+
+	if running, node, err := s.IsJobRunning("job"); err != nil || !running || node != "node-1" {
+		t.Fatalf("expected job running on node-1, got %t %q: %v", running, node, err)
+	}
+}
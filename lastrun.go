@@ -0,0 +1,80 @@
+package distcron
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrNoLastRun is returned from LastRun if the job has never run.
+var ErrNoLastRun = errors.New("job has not run")
+
+// RunInfo holds information about a single run of a job.
+type RunInfo struct {
+	Name     string        `json:"name"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	NodeID   string        `json:"node_id"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Succeeded reports if the run finished without an error.
+func (r RunInfo) Succeeded() bool {
+	return r.Error == ""
+}
+
+// LastRun returns information about the last run of the job with the given
+// name by any process. The information is stored in Redis when a job finishes
+// so it's shared between all processes and survives restarts. This is only
+// recorded when using the default Redis locker. If the job has never run
+// ErrNoLastRun is returned.
+func (s *Schedule) LastRun(name string) (RunInfo, error) {
+	var info RunInfo
+
+	data, err := redis.Bytes(do(s.redisPool(), "GET", s.jobKeys(name).lastRun(name)))
+	if errors.Is(err, redis.ErrNil) {
+		return info, ErrNoLastRun
+	}
+
+	if err != nil {
+		return info, fmt.Errorf("could not get last run: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("could not decode last run: %w", err)
+	}
+
+	return info, nil
+}
+
+// recordLastRun will store information about the run in Redis if the default
+// Redis locker is used.
+func (s *Schedule) recordLastRun(name string, started time.Time, d time.Duration, runErr error) {
+	if s.locker != nil {
+		return
+	}
+
+	info := RunInfo{
+		Name:     name,
+		Started:  started,
+		Duration: d,
+		NodeID:   s.nodeID,
+	}
+
+	if runErr != nil {
+		info.Error = runErr.Error()
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		s.logger.Error(err, "could not encode last run", s.jobFields(name, "error")...)
+		return
+	}
+
+	if _, err := do(s.redisPool(), "SET", s.jobKeys(name).lastRun(name), data); err != nil {
+		s.logger.Error(err, "could not store last run", s.jobFields(name, "error")...)
+	}
+}
@@ -0,0 +1,86 @@
+package distcron
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redsync/redsync"
+)
+
+// leaderTTL is the expiry of the leader lock. The leader renews the lock three
+// times during this period and followers try to take it at the same interval.
+const leaderTTL = 15 * time.Second
+
+// WithLeaderElection will elect a single leader among all processes which is
+// the only one that will run any jobs. All other processes stay idle and try to
+// take over if the leader stops renewing its lock, e.g. if it dies. The per job
+// lock is still used to ensure a job started by a previous leader isn't started
+// again until it's finished. The election is made with Redis even if a custom
+// Locker is used.
+func (s *Schedule) WithLeaderElection() *Schedule {
+	s.leaderElection = true
+	return s
+}
+
+// IsLeader reports if this process is the elected leader. This is always false
+// if leader election isn't enabled.
+func (s *Schedule) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.leader
+}
+
+func (s *Schedule) setLeader(leader bool) {
+	s.mu.Lock()
+	changed := s.leader != leader
+	s.leader = leader
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if leader {
+		s.logger.Info("elected as leader", "node", s.nodeID)
+	} else {
+		s.logger.Info("no longer leader", "node", s.nodeID)
+	}
+}
+
+// elect will try to become the leader and renew the leader lock until the
+// context is cancelled. When the context is cancelled the lock is released so
+// another process can take over immediately.
+func (s *Schedule) elect(ctx context.Context, pools []redsync.Pool) {
+	var (
+		rs     = redsync.New(pools)
+		ticker = time.NewTicker(leaderTTL / 3)
+		mutex  *redsync.Mutex
+	)
+
+	defer ticker.Stop()
+
+	for {
+		if mutex == nil {
+			m := rs.NewMutex(s.keys.leader(), redsync.SetExpiry(leaderTTL), redsync.SetTries(1))
+			if err := m.Lock(); err == nil {
+				mutex = m
+				s.setLeader(true)
+			}
+		} else if ok, err := mutex.Extend(); !ok || err != nil {
+			mutex = nil
+			s.setLeader(false)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if mutex != nil {
+				_, _ = mutex.Unlock()
+				s.setLeader(false)
+			}
+
+			return
+		}
+	}
+}
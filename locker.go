@@ -0,0 +1,500 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-redsync/redsync"
+	"github.com/gomodule/redigo/redis"
+	"github.com/robfig/cron/v3"
+)
+
+// Locker is used to ensure that only one process runs a job at the time. The
+// default locker uses Redis but any backend with support for distributed locks
+// such as etcd or Consul can be used.
+type Locker interface {
+	// Acquire will try to acquire the lock for the job with the given name. If
+	// the lock is already held by someone else false will be returned. If the
+	// lock was acquired the release function must be called when the job is
+	// finished.
+	Acquire(name string) (bool, func(), error)
+}
+
+// Holder can be implemented by a Locker to tell which node holds the lock for a
+// job. This is used to log the node that won the lock. The default Redis locker
+// implements this interface.
+type Holder interface {
+	// Holder returns the ID of the node holding the lock for the job with the
+	// given name or an empty string if the lock isn't held.
+	Holder(name string) (string, error)
+}
+
+// lockHolder returns the node holding the lock for the job if the locker
+// implements Holder, otherwise "unknown" is returned.
+func (s *Schedule) lockHolder(locker Locker, name string) string {
+	holder, ok := locker.(Holder)
+	if !ok {
+		return "unknown"
+	}
+
+	nodeID, err := holder.Holder(name)
+	if err != nil {
+		s.logger.Error(err, "could not get lock holder", s.jobFields(name, "error")...)
+		return "unknown"
+	}
+
+	return nodeID
+}
+
+// InMemoryLocker is a Locker that keeps the locks in memory. It does NOT give
+// any distributed guarantees since locks are only shared within the same
+// process and should only be used for tests where no Redis is available.
+type InMemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]struct{}
+	slots map[string]int
+	done  map[string]struct{}
+}
+
+// NewInMemoryLocker returns a new InMemoryLocker.
+func NewInMemoryLocker() *InMemoryLocker {
+	return &InMemoryLocker{
+		locks: map[string]struct{}{},
+		done:  map[string]struct{}{},
+	}
+}
+
+// Acquire will acquire the lock for the job unless it's already held.
+func (l *InMemoryLocker) Acquire(name string) (bool, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locks == nil {
+		l.locks = map[string]struct{}{}
+	}
+
+	if _, ok := l.locks[name]; ok {
+		return false, nil, nil
+	}
+
+	l.locks[name] = struct{}{}
+
+	return true, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.locks, name)
+	}, nil
+}
+
+// IsDone reports if the job is marked as done.
+func (l *InMemoryLocker) IsDone(name string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.done[name]
+
+	return ok, nil
+}
+
+// MarkDone will mark the job as done.
+func (l *InMemoryLocker) MarkDone(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.done == nil {
+		l.done = map[string]struct{}{}
+	}
+
+	l.done[name] = struct{}{}
+
+	return nil
+}
+
+// redisLocker is the default Locker which writes a key for each job being run.
+type redisLocker struct {
+	pool   redsync.Pool
+	rs     *redsync.Redsync
+	ttl    time.Duration
+	nodeID string
+	keys   func(name string) keys
+	logger cron.Logger
+
+	// retries is the number of times to retry a Redis operation failing due to
+	// connection errors and backoff is the initial time to wait between them.
+	retries int
+	backoff time.Duration
+
+	// quorum is true if multiple endpoints are used in which case the key is
+	// written while holding a redsync mutex. mutexOptions are passed to
+	// redsync when creating the mutex.
+	quorum       bool
+	mutexOptions []redsync.Option
+
+	// lockTries and lockDelay are the number of tries to lock the mutex and
+	// the delay between them. The tries are made by the locker instead of
+	// redsync to stop trying as soon as ctx is cancelled.
+	lockTries int
+	lockDelay time.Duration
+	ctx       context.Context
+
+	// owned holds the release function for every key currently owned by
+	// this process, keyed by the key and the value.
+	ownedMu sync.Mutex
+	owned   map[string]func()
+
+	// values holds the value written to the job key for each job currently
+	// owned by this process, encoded with encode.
+	values  map[string][]byte
+	encode  func(StatusInfo) ([]byte, error)
+	decode  func([]byte) (StatusInfo, error)
+	maxSize int
+}
+
+// newRedisLocker will create a Redis locker with the options from the
+// schedule. The job keys are written to pool while the mutexes are locked in
+// all mutexPools. No new locks are acquired once the context is cancelled.
+func (s *Schedule) newRedisLocker(ctx context.Context, pool redsync.Pool, mutexPools []redsync.Pool) *redisLocker {
+	return &redisLocker{
+		pool:    pool,
+		rs:      redsync.New(mutexPools),
+		ttl:     s.jobTTL,
+		nodeID:  s.nodeID,
+		keys:    s.jobKeys,
+		logger:  s.logger,
+		retries: s.reconnectRetries,
+		backoff: s.reconnectBackoff,
+
+		quorum:       len(mutexPools) > 1,
+		mutexOptions: s.lockOptions,
+		lockTries:    s.lockTries,
+		lockDelay:    s.lockDelay,
+		ctx:          ctx,
+
+		encode:  s.encodeStatus,
+		decode:  s.decodeStatus,
+		maxSize: s.statusMaxSize,
+	}
+}
+
+// Acquire will atomically write a key for the specific job if it doesn't
+// already exist to avoid other processes starting the same. When the returned
+// release function is called, the key holding the lock will be removed. When
+// multiple endpoints are used the key is only written while holding the global
+// mutex for the job which is locked in a majority of the endpoints.
+func (l *redisLocker) Acquire(name string) (bool, func(), error) {
+	if err := l.ctx.Err(); err != nil {
+		return false, nil, err
+	}
+
+	if l.quorum {
+		mutex := l.mutex(name)
+
+		// Failing to take the mutex means that another process holds it
+		// which is expected, any other error is a problem with Redis.
+		if err := l.lock(mutex); errors.Is(err, redsync.ErrFailed) {
+			return false, nil, nil
+		} else if err != nil {
+			return false, nil, fmt.Errorf("could not obtain lock: %w", err)
+		}
+
+		defer l.unlock(name, mutex)
+	}
+
+	// Setting the key only if it doesn't exist both checks if the job is
+	// already on-going and tells other processes that we will run it in one
+	// operation.
+	value, err := l.encode(StatusInfo{
+		Name:    name,
+		NodeID:  l.nodeID,
+		PID:     os.Getpid(),
+		Started: time.Now(),
+	})
+	if err != nil {
+		return false, nil, fmt.Errorf("could not encode job key: %w", err)
+	}
+
+	if l.maxSize > 0 && len(value) > l.maxSize {
+		l.logger.Info("encoded job key too large, writing marker", "job", name, "node", l.nodeID, "size", len(value), "max", l.maxSize)
+		value = []byte(statusMarker)
+	}
+
+	key := l.keys(name).status(name)
+
+	args := []interface{}{key, value, "NX"}
+	if l.ttl > 0 {
+		args = append(args, "PX", int64(l.ttl/time.Millisecond))
+	}
+
+	reply, err := l.do("SET", args...)
+	if err != nil {
+		return false, nil, fmt.Errorf("could not set job key: %w", err)
+	}
+
+	// A nil reply means that the key already exists.
+	if reply == nil {
+		return false, nil, nil
+	}
+
+	l.setValue(name, value)
+
+	return true, l.own(key, func() { l.release(name, key, value) }), nil
+}
+
+// setValue will remember the value written to the job key to be able to check
+// that the key is still owned by this process. An empty value removes it.
+func (l *redisLocker) setValue(name string, value []byte) {
+	l.ownedMu.Lock()
+	defer l.ownedMu.Unlock()
+
+	if l.values == nil {
+		l.values = map[string][]byte{}
+	}
+
+	if value == nil {
+		delete(l.values, name)
+		return
+	}
+
+	l.values[name] = value
+}
+
+// value returns the value written to the job key by this process.
+func (l *redisLocker) value(name string) []byte {
+	l.ownedMu.Lock()
+	defer l.ownedMu.Unlock()
+
+	return l.values[name]
+}
+
+// own will remember that the key is owned by this process until the returned
+// function is called, which calls release. The value must be unique for keys
+// that can be owned multiple times.
+func (l *redisLocker) own(key string, release func()) func() {
+	l.ownedMu.Lock()
+	defer l.ownedMu.Unlock()
+
+	if l.owned == nil {
+		l.owned = map[string]func(){}
+	}
+
+	var once sync.Once
+
+	l.owned[key] = func() { once.Do(release) }
+
+	return func() {
+		l.ownedMu.Lock()
+		f := l.owned[key]
+		delete(l.owned, key)
+		l.ownedMu.Unlock()
+
+		if f != nil {
+			f()
+		}
+	}
+}
+
+// releaseAll will release every key owned by this process. This is used when
+// the teardown process times out to let other processes take over the jobs
+// that are still running without waiting for the keys to expire.
+func (l *redisLocker) releaseAll() {
+	l.ownedMu.Lock()
+	owned := l.owned
+	l.owned = nil
+	l.ownedMu.Unlock()
+
+	for _, release := range owned {
+		release()
+	}
+}
+
+// release will remove the key for the job but only if we're still the owner.
+// If the key has expired and been taken by someone else we must not remove it.
+// The key is passed since the job might have been removed while running.
+func (l *redisLocker) release(name, key string, value []byte) {
+	defer l.setValue(name, nil)
+
+	deleted, err := redis.Bool(l.script(deleteIfOwnerScript, key, value))
+	if err != nil {
+		l.logger.Error(err, "could not remove job lock", "job", name, "node", l.nodeID)
+	} else if !deleted {
+		l.logger.Info("job lock not owned by this process, not removing", "job", name, "node", l.nodeID)
+	}
+}
+
+// Holder returns the node ID written to the job key. If the key doesn't exist,
+// e.g. because it was just released, or if the key only holds the marker
+// written for a status that is too large an empty string is returned.
+func (l *redisLocker) Holder(name string) (string, error) {
+	value, err := redis.Bytes(l.do("GET", l.keys(name).status(name)))
+	if errors.Is(err, redis.ErrNil) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return holderFromStatus(l.decode, value), nil
+}
+
+// Extend will reset the TTL of the job key if it's still owned by this
+// process.
+func (l *redisLocker) Extend(name string) (bool, error) {
+	if l.ttl <= 0 {
+		// The key never expires so there's nothing to extend.
+		return true, nil
+	}
+
+	value := l.value(name)
+	if value == nil {
+		return false, nil
+	}
+
+	return redis.Bool(l.script(
+		extendIfOwnerScript,
+		l.keys(name).status(name),
+		value,
+		int64(l.ttl/time.Millisecond),
+	))
+}
+
+// extendIfOwnerScript will set a new TTL in milliseconds on the key only if the
+// value matches.
+var extendIfOwnerScript = redis.NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	else
+		return 0
+	end
+`)
+
+// deleteIfOwnerScript will delete the key only if the value matches.
+var deleteIfOwnerScript = redis.NewScript(1, `
+	if redis.call("GET", KEYS[1]) == ARGV[1] then
+		return redis.call("DEL", KEYS[1])
+	else
+		return 0
+	end
+`)
+
+// script will run the script and retry it if it fails due to a connection
+// error.
+func (l *redisLocker) script(script *redis.Script, keysAndArgs ...interface{}) (interface{}, error) {
+	var (
+		reply interface{}
+		err   error
+	)
+
+	err = l.retry(func() error {
+		conn := l.pool.Get()
+		defer conn.Close()
+
+		reply, err = script.Do(conn, keysAndArgs...)
+
+		return err
+	})
+
+	return reply, err
+}
+
+// IsDone reports if the done marker for the job exists.
+func (l *redisLocker) IsDone(name string) (bool, error) {
+	return redis.Bool(l.do("EXISTS", l.keys(name).done(name)))
+}
+
+// MarkDone will write the done marker for the job. The marker doesn't expire
+// to ensure the job won't run again even after all processes are restarted.
+func (l *redisLocker) MarkDone(name string) error {
+	_, err := l.do("SET", l.keys(name).done(name), time.Now().Unix())
+	return err
+}
+
+func doneKey(name string) string {
+	return fmt.Sprintf("DONE-%s", name)
+}
+
+// do will run the command and retry it if it fails due to a connection error.
+func (l *redisLocker) do(cmd string, args ...interface{}) (interface{}, error) {
+	var (
+		reply interface{}
+		err   error
+	)
+
+	err = l.retry(func() error {
+		reply, err = do(l.pool, cmd, args...)
+		return err
+	})
+
+	return reply, err
+}
+
+// lock will try to lock the mutex until it succeeds, the number of tries is
+// reached or the context for the locker is cancelled. Each try is retried if it
+// fails due to a connection error.
+func (l *redisLocker) lock(mutex *redsync.Mutex) error {
+	for try := 1; ; try++ {
+		err := l.retry(mutex.Lock)
+		if err == nil || try >= l.lockTries || !errors.Is(err, redsync.ErrFailed) {
+			return err
+		}
+
+		select {
+		case <-time.After(l.lockDelay):
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		}
+	}
+}
+
+// retry will call f until it succeeds, fails with an error that isn't caused by
+// the connection, the number of retries is reached or the context for the
+// locker is cancelled. The time to wait between each retry is doubled for every
+// attempt.
+func (l *redisLocker) retry(f func() error) error {
+	delay := l.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt > l.retries || !isConnectionError(err) {
+			return err
+		}
+
+		l.logger.Info("redis operation failed, reconnecting", "attempt", attempt, "error", err.Error())
+
+		select {
+		case <-time.After(delay):
+		case <-l.ctx.Done():
+			return err
+		}
+
+		delay *= 2
+	}
+}
+
+// isConnectionError reports if the error is caused by a connection problem and
+// not by an error reply from Redis or a lock held by someone else.
+func isConnectionError(err error) bool {
+	if errors.Is(err, redsync.ErrFailed) {
+		return false
+	}
+
+	var redisErr redis.Error
+
+	return !errors.As(err, &redisErr)
+}
+
+func (l *redisLocker) mutex(name string) *redsync.Mutex {
+	options := append(append([]redsync.Option{}, l.mutexOptions...), redsync.SetTries(1))
+	return l.rs.NewMutex(l.keys(name).mutex(name), options...)
+}
+
+func (l *redisLocker) unlock(name string, mutex *redsync.Mutex) {
+	if ok, err := mutex.Unlock(); !ok || err != nil {
+		l.logger.Error(errors.New("unlock failed"), "unlock did not return a true value", "job", name, "node", l.nodeID)
+	}
+}
@@ -0,0 +1,154 @@
+package distcron
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRedisLockerKeyExpires(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	s.WithJobTTL(time.Minute)
+
+	locker := newTestLocker(s)
+
+	// Take the lock but never release it, like a process that crashes
+	// while running the job.
+	ok, _, err := locker.Acquire("job")
+	if err != nil || !ok {
+		t.Fatalf("could not acquire lock: %v", err)
+	}
+
+	if !mr.Exists(s.keys.status("job")) {
+		t.Fatal("job key not written")
+	}
+
+	if ok, _, _ := locker.Acquire("job"); ok {
+		t.Fatal("lock acquired while held")
+	}
+
+	mr.FastForward(time.Minute + time.Second)
+
+	if mr.Exists(s.keys.status("job")) {
+		t.Fatal("job key did not expire")
+	}
+
+	if ok, _, err := locker.Acquire("job"); err != nil || !ok {
+		t.Fatalf("could not acquire lock after expiry: %v", err)
+	}
+}
+
+func TestRedisLockerReleaseChecksOwner(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	s.WithJobTTL(time.Minute)
+
+	port, _ := strconv.Atoi(mr.Port())
+	other := New().
+		WithRedisHost(mr.Host()).
+		WithRedisPort(port).
+		WithNodeID("node-2").
+		WithJobTTL(time.Minute)
+
+	ok, release, err := newTestLocker(s).Acquire("job")
+	if err != nil || !ok {
+		t.Fatalf("could not acquire lock: %v", err)
+	}
+
+	// Let the key expire while the first job is still running so another
+	// process can take it.
+	mr.FastForward(time.Minute + time.Second)
+
+	otherLocker := newTestLocker(other)
+
+	if ok, _, err := otherLocker.Acquire("job"); err != nil || !ok {
+		t.Fatalf("could not acquire expired lock: %v", err)
+	}
+
+	release()
+
+	if !mr.Exists(s.keys.status("job")) {
+		t.Fatal("key owned by another process removed")
+	}
+
+	if holder, err := otherLocker.Holder("job"); err != nil || holder != "node-2" {
+		t.Fatalf("expected lock held by node-2, got %q: %v", holder, err)
+	}
+}
+
+func TestRedisLockerConcurrentAcquire(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	port, _ := strconv.Atoi(mr.Port())
+	other := New().
+		WithRedisHost(mr.Host()).
+		WithRedisPort(port).
+		WithNodeID("node-2")
+
+	lockers := []*redisLocker{newTestLocker(s), newTestLocker(other)}
+
+	for i := 0; i < 50; i++ {
+		var (
+			name     = "job-" + strconv.Itoa(i)
+			start    = make(chan struct{})
+			acquired int32
+			wg       sync.WaitGroup
+		)
+
+		for _, locker := range lockers {
+			wg.Add(1)
+
+			go func(locker *redisLocker) {
+				defer wg.Done()
+
+				<-start
+
+				ok, _, err := locker.Acquire(name)
+				if err != nil {
+					t.Errorf("could not acquire lock: %v", err)
+				}
+
+				if ok {
+					atomic.AddInt32(&acquired, 1)
+				}
+			}(locker)
+		}
+
+		close(start)
+		wg.Wait()
+
+		if acquired != 1 {
+			t.Fatalf("expected exactly one process to acquire %s, got %d", name, acquired)
+		}
+	}
+}
+
+func TestRedisLockerRetryStopsWhenCancelled(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	s.WithReconnect(5, time.Second)
+
+	// Every operation fails with a connection error once Redis is gone.
+	mr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	locker := s.newRedisLocker(ctx, s.redisPool(), s.redisPools())
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+
+	if _, err := locker.Holder("job"); err == nil {
+		t.Fatal("expected an error without redis")
+	}
+
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Fatalf("expected retries to stop when cancelled, waited %s", elapsed)
+	}
+}
@@ -0,0 +1,31 @@
+package distcron
+
+import "time"
+
+// Metrics is used to observe job runs, e.g. with Prometheus counters and
+// histograms. All methods are called with the name of the job.
+type Metrics interface {
+	// JobStarted is called when a job acquired the lock and is started.
+	JobStarted(name string)
+
+	// JobCompleted is called when a job finished without an error.
+	JobCompleted(name string, d time.Duration)
+
+	// JobFailed is called when a job returned an error or panicked.
+	JobFailed(name string, d time.Duration, err error)
+
+	// JobSkipped is called when the job wasn't started because the lock or
+	// every slot was held by another process, the node is unhealthy according
+	// to WithHealthGate, the circuit set with WithCircuitBreaker is open or
+	// the previous run is still running in this process. Jobs that are
+	// disabled, paused or assigned to another node are not counted.
+	JobSkipped(name string)
+}
+
+// noopMetrics is used when no metrics are configured.
+type noopMetrics struct{}
+
+func (noopMetrics) JobStarted(string)                      {}
+func (noopMetrics) JobCompleted(string, time.Duration)     {}
+func (noopMetrics) JobFailed(string, time.Duration, error) {}
+func (noopMetrics) JobSkipped(string)                      {}
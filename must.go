@@ -0,0 +1,27 @@
+package distcron
+
+// MustAddJob works like AddJob but will panic if the spec is invalid or the name
+// is already used instead of returning the error when calling Run.
+func (s *Schedule) MustAddJob(spec, name string, f func(), opts ...JobOption) *Schedule {
+	s.mu.Lock()
+	errs := len(s.errs)
+	s.mu.Unlock()
+
+	s.AddJob(spec, name, f, opts...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.errs) > errs {
+		panic(s.errs[len(s.errs)-1])
+	}
+
+	return s
+}
+
+// MustRun works like Run but will panic if an error is returned.
+func (s *Schedule) MustRun() {
+	if err := s.Run(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,47 @@
+package distcron
+
+// LockOutcome is the outcome of an attempt to acquire the lock for a job.
+type LockOutcome int
+
+const (
+	// LockWon means that the lock was acquired and the job will run.
+	LockWon LockOutcome = iota
+
+	// LockLost means that the lock was held by another process.
+	LockLost
+
+	// LockError means that the lock couldn't be acquired due to an error.
+	LockError
+)
+
+// String returns the name of the outcome.
+func (o LockOutcome) String() string {
+	switch o {
+	case LockWon:
+		return "won"
+	case LockLost:
+		return "lost"
+	case LockError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// WithLockObserver sets a function that will be called with the outcome every
+// time a process tries to acquire the lock for a job. Unlike WithBeforeJob and
+// WithAfterJob this is called in every process, no matter if the lock was
+// acquired or not, which makes it useful in tests and for monitoring. Jobs
+// skipped before trying to acquire the lock, e.g. disabled jobs, are not
+// observed.
+func (s *Schedule) WithLockObserver(f func(name string, outcome LockOutcome)) *Schedule {
+	s.lockObserver = f
+	return s
+}
+
+// observeLock will call the lock observer if one is set.
+func (s *Schedule) observeLock(name string, outcome LockOutcome) {
+	if s.lockObserver != nil {
+		s.lockObserver(name, outcome)
+	}
+}
@@ -0,0 +1,109 @@
+package distcron
+
+import (
+	"sync"
+	"time"
+)
+
+// DoneMarker can be implemented by a Locker to persist that a job added with
+// AddOnceAt has run. Both the default Redis locker and the InMemoryLocker
+// implement this interface.
+type DoneMarker interface {
+	// IsDone reports if the job with the given name is marked as done.
+	IsDone(name string) (bool, error)
+
+	// MarkDone will mark the job with the given name as done.
+	MarkDone(name string) error
+}
+
+// onceRetryDelay is the time to wait before triggering a job added with
+// AddOnceAt again if it was skipped before it was known who runs it, e.g.
+// because it was disabled or the lock couldn't be acquired.
+const onceRetryDelay = 10 * time.Second
+
+// onceSchedule is a cron.Schedule that only triggers once. If the time has
+// already passed when the schedule is checked and the job hasn't been triggered
+// it will trigger on the next whole second.
+type onceSchedule struct {
+	mu      sync.Mutex
+	at      time.Time
+	retryAt time.Time
+	fired   bool
+}
+
+// Next returns the time to run the job until it has been triggered and the zero
+// time after that which means that cron will never run it again. Since it
+// doesn't depend on how many times it's called the job keeps its time when the
+// schedule is drained and resumed or stopped and started again.
+func (o *onceSchedule) Next(now time.Time) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.fired {
+		return time.Time{}
+	}
+
+	next := o.at
+	if o.retryAt.After(next) {
+		next = o.retryAt
+	}
+
+	if now.Before(next) {
+		return next
+	}
+
+	return now.Truncate(time.Second).Add(time.Second)
+}
+
+// fire marks the schedule as triggered. False is returned if it has already
+// been triggered or if it's triggered before it should be retried.
+func (o *onceSchedule) fire(now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.fired || now.Before(o.retryAt) {
+		return false
+	}
+
+	o.fired = true
+
+	return true
+}
+
+// retry will make the schedule trigger again at the given time.
+func (o *onceSchedule) retry(at time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.fired = false
+	o.retryAt = at
+}
+
+// isDone reports if the job is marked as done. If the locker doesn't support
+// marking jobs as done or if the state cannot be read false is returned.
+func (s *Schedule) isDone(locker Locker, name string) bool {
+	marker, ok := locker.(DoneMarker)
+	if !ok {
+		return false
+	}
+
+	done, err := marker.IsDone(name)
+	if err != nil {
+		s.logger.Error(err, "could not check if job is done", s.jobFields(name, "error")...)
+		return false
+	}
+
+	return done
+}
+
+// markDone will mark the job as done if the locker supports it.
+func (s *Schedule) markDone(locker Locker, name string) {
+	marker, ok := locker.(DoneMarker)
+	if !ok {
+		return
+	}
+
+	if err := marker.MarkDone(name); err != nil {
+		s.logger.Error(err, "could not mark job as done", s.jobFields(name, "error")...)
+	}
+}
@@ -0,0 +1,146 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOnceScheduleNext(t *testing.T) {
+	var (
+		at       = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+		schedule = &onceSchedule{at: at}
+	)
+
+	// Checking the schedule many times, e.g. when restarting it, must not
+	// change the time.
+	for i := 0; i < 3; i++ {
+		if next := schedule.Next(at.Add(-time.Minute)); !next.Equal(at) {
+			t.Fatalf("expected %s before the time, got %s", at, next)
+		}
+	}
+
+	if next := schedule.Next(at.Add(time.Minute)); !next.Equal(at.Add(time.Minute + time.Second)) {
+		t.Fatalf("expected the next second when the time has passed, got %s", next)
+	}
+
+	if !schedule.fire(at) {
+		t.Fatal("expected the first trigger to fire")
+	}
+
+	if schedule.fire(at) {
+		t.Fatal("expected the second trigger not to fire")
+	}
+
+	if next := schedule.Next(at.Add(-time.Minute)); !next.IsZero() {
+		t.Fatalf("expected zero time after the job fired, got %s", next)
+	}
+}
+
+func TestOnceJobSurvivesDrain(t *testing.T) {
+	ran := make(chan struct{}, 2)
+
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		AddOnceAt(time.Now().Add(1500*time.Millisecond), "once", func() { ran <- struct{}{} })
+
+	stop := startSchedule(t, s)
+
+	if err := s.Drain(); err != nil {
+		t.Fatalf("could not drain schedule: %v", err)
+	}
+
+	if err := s.Resume(); err != nil {
+		t.Fatalf("could not resume schedule: %v", err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job never ran after the schedule was resumed")
+	}
+
+	if err := stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ran) != 0 {
+		t.Fatal("job ran more than once")
+	}
+}
+
+func TestOnceJobSkippedIsRetried(t *testing.T) {
+	var (
+		locker = NewInMemoryLocker()
+		runs   int
+	)
+
+	s := New().
+		WithLocker(locker).
+		AddOnceAt(time.Now().Add(-time.Minute), "once", func() { runs++ })
+
+	job := s.jobs[0]
+	schedule := job.schedule.(*onceSchedule)
+
+	s.DisableJob("once")
+	s.lock(context.Background(), locker, job)()
+
+	if len(s.jobs) != 1 {
+		t.Fatal("skipped job removed from the schedule")
+	}
+
+	if done, _ := locker.IsDone("once"); done || runs != 0 {
+		t.Fatal("skipped job marked as done")
+	}
+
+	if next := schedule.Next(time.Now()); time.Until(next) < onceRetryDelay/2 {
+		t.Fatalf("expected the job to be retried later, got %s", next)
+	}
+
+	// Triggering the job before it should be retried does nothing.
+	s.EnableJob("once")
+	s.lock(context.Background(), locker, job)()
+
+	if runs != 0 {
+		t.Fatal("job ran before it should be retried")
+	}
+
+	schedule.retry(time.Time{})
+	s.lock(context.Background(), locker, job)()
+
+	if runs != 1 {
+		t.Fatalf("expected 1 run, got %d", runs)
+	}
+
+	if len(s.jobs) != 0 {
+		t.Fatal("job not removed after it ran")
+	}
+
+	if done, _ := locker.IsDone("once"); !done {
+		t.Fatal("job not marked as done")
+	}
+}
+
+func TestOnceJobFailedIsNotDone(t *testing.T) {
+	locker := NewInMemoryLocker()
+
+	s := New().
+		WithLocker(locker).
+		AddOnceAt(time.Now().Add(-time.Minute), "once", func() {})
+
+	job := s.jobs[0]
+	job.run = func(context.Context) error {
+		return errors.New("failed")
+	}
+
+	s.lock(context.Background(), locker, job)()
+
+	if len(s.jobs) != 0 {
+		t.Fatal("job not removed after it ran")
+	}
+
+	if done, _ := locker.IsDone("once"); done {
+		t.Fatal("failed job marked as done")
+	}
+}
@@ -0,0 +1,456 @@
+package distcron
+
+import (
+	"crypto/tls"
+	"os"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/robfig/cron/v3"
+)
+
+// Option configures a Schedule. Every builder method on Schedule has an Option
+// form with the Opt suffix which makes it possible to compose a set of options,
+// e.g. to have a default set of options in a library and let callers append
+// their own overrides.
+type Option func(*Schedule)
+
+// NewWithOptions creates a new Schedule with default values and applies the
+// options in order.
+func NewWithOptions(opts ...Option) *Schedule {
+	return New().Apply(opts...)
+}
+
+// Apply will apply the options in order to the schedule.
+func (s *Schedule) Apply(opts ...Option) *Schedule {
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithLoggerOpt is the Option form of WithLogger.
+func WithLoggerOpt(l cron.Logger) Option {
+	return func(s *Schedule) {
+		s.WithLogger(l)
+	}
+}
+
+// WithRedisHostOpt is the Option form of WithRedisHost.
+func WithRedisHostOpt(host string) Option {
+	return func(s *Schedule) {
+		s.WithRedisHost(host)
+	}
+}
+
+// WithRedisPortOpt is the Option form of WithRedisPort.
+func WithRedisPortOpt(port int) Option {
+	return func(s *Schedule) {
+		s.WithRedisPort(port)
+	}
+}
+
+// WithRedisDBOpt is the Option form of WithRedisDB.
+func WithRedisDBOpt(db int) Option {
+	return func(s *Schedule) {
+		s.WithRedisDB(db)
+	}
+}
+
+// WithRedisUsernameOpt is the Option form of WithRedisUsername.
+func WithRedisUsernameOpt(username string) Option {
+	return func(s *Schedule) {
+		s.WithRedisUsername(username)
+	}
+}
+
+// WithRedisPasswordOpt is the Option form of WithRedisPassword.
+func WithRedisPasswordOpt(password string) Option {
+	return func(s *Schedule) {
+		s.WithRedisPassword(password)
+	}
+}
+
+// WithRedisURLOpt is the Option form of WithRedisURL. Since an Option can't
+// return an error an invalid URL is returned when the schedule is started.
+func WithRedisURLOpt(rawURL string) Option {
+	return func(s *Schedule) {
+		if err := s.WithRedisURL(rawURL); err != nil {
+			s.mu.Lock()
+			s.errs = append(s.errs, err)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// WithReconnectOpt is the Option form of WithReconnect.
+func WithReconnectOpt(maxRetries int, backoff time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithReconnect(maxRetries, backoff)
+	}
+}
+
+// WithLockOptionsOpt is the Option form of WithLockOptions.
+func WithLockOptionsOpt(expiry time.Duration, tries int, delay time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithLockOptions(expiry, tries, delay)
+	}
+}
+
+// WithTLSOpt is the Option form of WithTLS.
+func WithTLSOpt(config *tls.Config) Option {
+	return func(s *Schedule) {
+		s.WithTLS(config)
+	}
+}
+
+// WithTLSSkipVerifyOpt is the Option form of WithTLSSkipVerify.
+func WithTLSSkipVerifyOpt() Option {
+	return func(s *Schedule) {
+		s.WithTLSSkipVerify()
+	}
+}
+
+// WithMaxIdleOpt is the Option form of WithMaxIdle.
+func WithMaxIdleOpt(maxIdle int) Option {
+	return func(s *Schedule) {
+		s.WithMaxIdle(maxIdle)
+	}
+}
+
+// WithMaxActiveOpt is the Option form of WithMaxActive.
+func WithMaxActiveOpt(maxActive int) Option {
+	return func(s *Schedule) {
+		s.WithMaxActive(maxActive)
+	}
+}
+
+// WithIdleTimeoutOpt is the Option form of WithIdleTimeout.
+func WithIdleTimeoutOpt(timeout time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithIdleTimeout(timeout)
+	}
+}
+
+// WithWaitForConnectionOpt is the Option form of WithWaitForConnection.
+func WithWaitForConnectionOpt(wait bool) Option {
+	return func(s *Schedule) {
+		s.WithWaitForConnection(wait)
+	}
+}
+
+// WithSignalsOpt is the Option form of WithSignals.
+func WithSignalsOpt(signals ...os.Signal) Option {
+	return func(s *Schedule) {
+		s.WithSignals(signals...)
+	}
+}
+
+// WithLockerOpt is the Option form of WithLocker.
+func WithLockerOpt(l Locker) Option {
+	return func(s *Schedule) {
+		s.WithLocker(l)
+	}
+}
+
+// WithJobTTLOpt is the Option form of WithJobTTL.
+func WithJobTTLOpt(ttl time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithJobTTL(ttl)
+	}
+}
+
+// WithSecondsOpt is the Option form of WithSeconds.
+func WithSecondsOpt() Option {
+	return func(s *Schedule) {
+		s.WithSeconds()
+	}
+}
+
+// WithLocationOpt is the Option form of WithLocation.
+func WithLocationOpt(loc *time.Location) Option {
+	return func(s *Schedule) {
+		s.WithLocation(loc)
+	}
+}
+
+// WithTimezoneOpt is the Option form of WithTimezone.
+func WithTimezoneOpt(name string) Option {
+	return func(s *Schedule) {
+		s.WithTimezone(name)
+	}
+}
+
+// WithShutdownTimeoutOpt is the Option form of WithShutdownTimeout.
+func WithShutdownTimeoutOpt(timeout time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithShutdownTimeout(timeout)
+	}
+}
+
+// WithPanicPropagationOpt is the Option form of WithPanicPropagation.
+func WithPanicPropagationOpt(propagate bool) Option {
+	return func(s *Schedule) {
+		s.WithPanicPropagation(propagate)
+	}
+}
+
+// WithSkipIfRunningOpt is the Option form of WithSkipIfRunning.
+func WithSkipIfRunningOpt() Option {
+	return func(s *Schedule) {
+		s.WithSkipIfRunning()
+	}
+}
+
+// WithAllowOverlapOpt is the Option form of WithAllowOverlap.
+func WithAllowOverlapOpt() Option {
+	return func(s *Schedule) {
+		s.WithAllowOverlap()
+	}
+}
+
+// WithJobWrappersOpt is the Option form of WithJobWrappers.
+func WithJobWrappersOpt(wrappers ...cron.JobWrapper) Option {
+	return func(s *Schedule) {
+		s.WithJobWrappers(wrappers...)
+	}
+}
+
+// WithMetricsOpt is the Option form of WithMetrics.
+func WithMetricsOpt(m Metrics) Option {
+	return func(s *Schedule) {
+		s.WithMetrics(m)
+	}
+}
+
+// WithBeforeJobOpt is the Option form of WithBeforeJob.
+func WithBeforeJobOpt(f func(name string)) Option {
+	return func(s *Schedule) {
+		s.WithBeforeJob(f)
+	}
+}
+
+// WithAfterJobOpt is the Option form of WithAfterJob.
+func WithAfterJobOpt(f func(name string, d time.Duration, err error)) Option {
+	return func(s *Schedule) {
+		s.WithAfterJob(f)
+	}
+}
+
+// WithSentinelOpt is the Option form of WithSentinel.
+func WithSentinelOpt(addrs []string, masterName string) Option {
+	return func(s *Schedule) {
+		s.WithSentinel(addrs, masterName)
+	}
+}
+
+// WithJitterOpt is the Option form of WithJitter.
+func WithJitterOpt(max time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithJitter(max)
+	}
+}
+
+// WithJitterSeedOpt is the Option form of WithJitterSeed.
+func WithJitterSeedOpt(seed int64) Option {
+	return func(s *Schedule) {
+		s.WithJitterSeed(seed)
+	}
+}
+
+// WithLeaderElectionOpt is the Option form of WithLeaderElection.
+func WithLeaderElectionOpt() Option {
+	return func(s *Schedule) {
+		s.WithLeaderElection()
+	}
+}
+
+// WithNodeIDOpt is the Option form of WithNodeID.
+func WithNodeIDOpt(id string) Option {
+	return func(s *Schedule) {
+		s.WithNodeID(id)
+	}
+}
+
+// WithKeyPrefixOpt is the Option form of WithKeyPrefix.
+func WithKeyPrefixOpt(prefix string) Option {
+	return func(s *Schedule) {
+		s.WithKeyPrefix(prefix)
+	}
+}
+
+// WithLockExtensionOpt is the Option form of WithLockExtension.
+func WithLockExtensionOpt(interval time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithLockExtension(interval)
+	}
+}
+
+// WithLoadBalancingOpt is the Option form of WithLoadBalancing.
+func WithLoadBalancingOpt() Option {
+	return func(s *Schedule) {
+		s.WithLoadBalancing()
+	}
+}
+
+// WithOnFailureOpt is the Option form of WithOnFailure.
+func WithOnFailureOpt(f func(name string, err error)) Option {
+	return func(s *Schedule) {
+		s.WithOnFailure(f)
+	}
+}
+
+// WithClockOpt is the Option form of WithClock.
+func WithClockOpt(clock Clock) Option {
+	return func(s *Schedule) {
+		s.WithClock(clock)
+	}
+}
+
+// WithRedisSocketOpt is the Option form of WithRedisSocket.
+func WithRedisSocketOpt(path string) Option {
+	return func(s *Schedule) {
+		s.WithRedisSocket(path)
+	}
+}
+
+// WithRedisEndpointsOpt is the Option form of WithRedisEndpoints.
+func WithRedisEndpointsOpt(endpoints []RedisEndpoint) Option {
+	return func(s *Schedule) {
+		s.WithRedisEndpoints(endpoints)
+	}
+}
+
+// WithTracerOpt is the Option form of WithTracer.
+func WithTracerOpt(t Tracer) Option {
+	return func(s *Schedule) {
+		s.WithTracer(t)
+	}
+}
+
+// WithHealthGateOpt is the Option form of WithHealthGate.
+func WithHealthGateOpt(f func() bool) Option {
+	return func(s *Schedule) {
+		s.WithHealthGate(f)
+	}
+}
+
+// WithCronOpt is the Option form of WithCron.
+func WithCronOpt(c *cron.Cron) Option {
+	return func(s *Schedule) {
+		s.WithCron(c)
+	}
+}
+
+// WithStartupPingOpt is the Option form of WithStartupPing.
+func WithStartupPingOpt(enabled bool, retries int, delay time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithStartupPing(enabled, retries, delay)
+	}
+}
+
+// WithDryRunOpt is the Option form of WithDryRun.
+func WithDryRunOpt() Option {
+	return func(s *Schedule) {
+		s.WithDryRun()
+	}
+}
+
+// WithOverrunPolicyOpt is the Option form of WithOverrunPolicy.
+func WithOverrunPolicyOpt(policy OverrunPolicy) Option {
+	return func(s *Schedule) {
+		s.WithOverrunPolicy(policy)
+	}
+}
+
+// WithFairnessOpt is the Option form of WithFairness.
+func WithFairnessOpt() Option {
+	return func(s *Schedule) {
+		s.WithFairness()
+	}
+}
+
+// WithReloadSignalOpt is the Option form of WithReloadSignal.
+func WithReloadSignalOpt(sig os.Signal) Option {
+	return func(s *Schedule) {
+		s.WithReloadSignal(sig)
+	}
+}
+
+// WithRedisTimeoutOpt is the Option form of WithRedisTimeout.
+func WithRedisTimeoutOpt(connect, read, write time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithRedisTimeout(connect, read, write)
+	}
+}
+
+// WithLockObserverOpt is the Option form of WithLockObserver.
+func WithLockObserverOpt(f func(name string, outcome LockOutcome)) Option {
+	return func(s *Schedule) {
+		s.WithLockObserver(f)
+	}
+}
+
+// WithForceExitAfterOpt is the Option form of WithForceExitAfter.
+func WithForceExitAfterOpt(d time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithForceExitAfter(d)
+	}
+}
+
+// WithStatusEncoderOpt is the Option form of WithStatusEncoder.
+func WithStatusEncoderOpt(encode func(StatusInfo) ([]byte, error), decode func([]byte) (StatusInfo, error)) Option {
+	return func(s *Schedule) {
+		s.WithStatusEncoder(encode, decode)
+	}
+}
+
+// WithRedisClusterOpt is the Option form of WithRedisCluster.
+func WithRedisClusterOpt(nodes []string) Option {
+	return func(s *Schedule) {
+		s.WithRedisCluster(nodes)
+	}
+}
+
+// WithSharedPauseOpt is the Option form of WithSharedPause.
+func WithSharedPauseOpt() Option {
+	return func(s *Schedule) {
+		s.WithSharedPause()
+	}
+}
+
+// WithCollectErrorsOpt is the Option form of WithCollectErrors.
+func WithCollectErrorsOpt() Option {
+	return func(s *Schedule) {
+		s.WithCollectErrors()
+	}
+}
+
+// WithRedisPoolOpt is the Option form of WithRedisPool.
+func WithRedisPoolOpt(pool *redis.Pool) Option {
+	return func(s *Schedule) {
+		s.WithRedisPool(pool)
+	}
+}
+
+// WithCircuitBreakerOpt is the Option form of WithCircuitBreaker.
+func WithCircuitBreakerOpt(threshold int, cooldown time.Duration) Option {
+	return func(s *Schedule) {
+		s.WithCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// WithKeyNamerOpt is the Option form of WithKeyNamer.
+func WithKeyNamerOpt(namer func(jobName string) (mutexKey, statusKey string)) Option {
+	return func(s *Schedule) {
+		s.WithKeyNamer(namer)
+	}
+}
+
+// WithStatusMaxSizeOpt is the Option form of WithStatusMaxSize.
+func WithStatusMaxSizeOpt(size int) Option {
+	return func(s *Schedule) {
+		s.WithStatusMaxSize(size)
+	}
+}
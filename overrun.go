@@ -0,0 +1,152 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrJobOverrun is reported when a job is triggered while still running with
+// OverrunError.
+var ErrJobOverrun = errors.New("job still running when triggered")
+
+// OverrunPolicy decides what happens when a job is triggered while the previous
+// run is still running in the same process, see WithOverrunPolicy.
+type OverrunPolicy int
+
+const (
+	// OverrunSkip will skip the run. This is the default.
+	OverrunSkip OverrunPolicy = iota
+
+	// OverrunQueue will wait for the previous run to finish and then run the
+	// job immediately. At most one run is queued for each job, if the job is
+	// triggered while a run is already queued it's skipped.
+	OverrunQueue
+
+	// OverrunError will skip the run and report ErrJobOverrun to the function
+	// set with WithOnFailure.
+	OverrunError
+)
+
+// WithOverrunPolicy sets what to do when a job is triggered while the previous
+// run is still running in this process. Other processes will see that the lock
+// is held and always skip the job. This has no effect if WithAllowOverlap is
+// used. OverrunSkip is used by default.
+func (s *Schedule) WithOverrunPolicy(policy OverrunPolicy) *Schedule {
+	s.overrunPolicy = policy
+	return s
+}
+
+// handleOverrun is called when the job is triggered while still running and
+// reports if the job should run. With OverrunQueue this will block until the
+// previous run is finished.
+func (s *Schedule) handleOverrun(ctx context.Context, name string) bool {
+	switch s.overrunPolicy {
+	case OverrunQueue:
+		finished, ok := s.enqueue(name)
+		if !ok {
+			s.logger.Info("job is still running and a run is already queued, skipping", s.jobFields(name, "still_running")...)
+			return false
+		}
+
+		s.logger.Info("job is still running, queueing", s.jobFields(name, "queued")...)
+
+		defer s.dequeue(name)
+
+		select {
+		case <-finished:
+			return true
+		case <-ctx.Done():
+			s.logger.Info("schedule stopped while queued, skipping", s.jobFields(name, "stopped")...)
+			return false
+		}
+	case OverrunError:
+		s.logger.Error(ErrJobOverrun, "job is still running, skipping", s.jobFields(name, "still_running")...)
+		s.notifyFailure(name, ErrJobOverrun)
+	default:
+		s.logger.Info("job is still running in this process, skipping", s.jobFields(name, "still_running")...)
+	}
+
+	return false
+}
+
+// enqueue will queue a run of the job unless one is already queued. The
+// returned channel is closed when the current run is finished.
+func (s *Schedule) enqueue(name string) (<-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queued == nil {
+		s.queued = map[string]bool{}
+	}
+
+	if s.queued[name] {
+		return nil, false
+	}
+
+	s.queued[name] = true
+
+	// The job might have finished since it was checked.
+	if s.running[name] <= 0 {
+		finished := make(chan struct{})
+		close(finished)
+
+		return finished, true
+	}
+
+	if s.finished == nil {
+		s.finished = map[string]chan struct{}{}
+	}
+
+	if _, ok := s.finished[name]; !ok {
+		s.finished[name] = make(chan struct{})
+	}
+
+	return s.finished[name], true
+}
+
+// dequeue will remove the queued run of the job.
+func (s *Schedule) dequeue(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.queued, name)
+}
+
+// notifyFinished will let a queued run of the job know that the job is
+// finished.
+func (s *Schedule) notifyFinished(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if finished, ok := s.finished[name]; ok {
+		close(finished)
+		delete(s.finished, name)
+	}
+}
+
+// warnLongRun will log a warning if the job ran for longer than the interval
+// between its runs starting at the time it was started. Such a job will overlap
+// with its next run which is skipped by default, something that is easy to
+// miss. Jobs without a recurring schedule are never reported.
+func (s *Schedule) warnLongRun(job *Job, start time.Time, duration time.Duration) {
+	// Jobs added with AddInterval never overlap.
+	if job.interval > 0 {
+		return
+	}
+
+	runs := s.upcomingRuns(job, start.In(s.location), 2)
+	if len(runs) < 2 {
+		return
+	}
+
+	interval := runs[1].Sub(runs[0])
+	if duration <= interval {
+		return
+	}
+
+	s.logger.Info(
+		"job ran longer than its schedule interval and will overlap with the next run",
+		s.jobFields(job.Name, "long_run", "duration", duration.String(), "interval", interval.String())...,
+	)
+}
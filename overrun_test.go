@@ -0,0 +1,121 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOverrunPolicy(t *testing.T) {
+	cases := []struct {
+		description  string
+		policy       OverrunPolicy
+		expectedRuns int32
+		expectError  bool
+	}{
+		{
+			description:  "skip",
+			policy:       OverrunSkip,
+			expectedRuns: 1,
+		},
+		{
+			description:  "queue",
+			policy:       OverrunQueue,
+			expectedRuns: 2,
+		},
+		{
+			description:  "error",
+			policy:       OverrunError,
+			expectedRuns: 1,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.description, func(t *testing.T) {
+			failures := make(chan error, 3)
+
+			s := New().
+				WithOverrunPolicy(tc.policy).
+				WithOnFailure(func(_ string, err error) { failures <- err }).
+				AddJob("* * * * *", "slow", func() {})
+
+			var runs int32
+
+			job := s.jobs[0]
+			unblock, wait := startSlowRun(t, s, grantLocker{}, job, &runs)
+
+			queued := make(chan struct{})
+
+			// Trigger the job while it's running. With OverrunQueue this
+			// blocks until the first run is finished.
+			go func() {
+				defer close(queued)
+				s.lock(context.Background(), grantLocker{}, job)()
+			}()
+
+			if tc.policy == OverrunQueue {
+				waitForQueued(t, s, job.Name)
+
+				// Only one run is queued so this is skipped.
+				s.lock(context.Background(), grantLocker{}, job)()
+			} else {
+				<-queued
+			}
+
+			if got := atomic.LoadInt32(&runs); got != 1 {
+				t.Fatalf("expected 1 run while the job is running, got %d", got)
+			}
+
+			unblock()
+			wait()
+			<-queued
+
+			if got := atomic.LoadInt32(&runs); got != tc.expectedRuns {
+				t.Fatalf("expected %d runs, got %d", tc.expectedRuns, got)
+			}
+
+			if !tc.expectError {
+				if len(failures) != 0 {
+					t.Fatalf("unexpected failure: %v", <-failures)
+				}
+
+				return
+			}
+
+			select {
+			case err := <-failures:
+				if !errors.Is(err, ErrJobOverrun) {
+					t.Fatalf("expected ErrJobOverrun, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("overrun never reported")
+			}
+		})
+	}
+}
+
+// waitForQueued will wait until a run of the job is queued.
+func waitForQueued(t *testing.T, s *Schedule, name string) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		s.mu.Lock()
+		queued := s.queued[name]
+		s.mu.Unlock()
+
+		if queued {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("job never queued")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,101 @@
+package distcron
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrUnknownJob is returned when referring to a job that isn't added to the
+// schedule.
+var ErrUnknownJob = errors.New("unknown job")
+
+// WithSharedPause will store the paused state set with PauseJob in Redis so a
+// job paused in one process is paused in every process sharing the same Redis
+// database. Every process checks the state each time the job is triggered. The
+// state is stored in Redis even if a custom Locker is used.
+func (s *Schedule) WithSharedPause() *Schedule {
+	s.sharedPause = true
+	return s
+}
+
+// PauseJob will pause the job with the given name. A paused job is skipped
+// before trying to take the lock every time it's triggered until it's unpaused
+// with UnpauseJob, while other jobs keep running. A running job is not
+// affected. Unlike DisableJob the state is stored in Redis when using
+// WithSharedPause to pause the job in every process. ErrUnknownJob is returned
+// if no job has the given name.
+func (s *Schedule) PauseJob(name string) error {
+	return s.setPaused(name, true)
+}
+
+// UnpauseJob will unpause a job paused with PauseJob. ErrUnknownJob is returned
+// if no job has the given name.
+func (s *Schedule) UnpauseJob(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Schedule) setPaused(name string, paused bool) error {
+	found := false
+
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.Name == name {
+			job.paused = paused
+			found = true
+		}
+	}
+	s.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("%w %s", ErrUnknownJob, name)
+	}
+
+	if !s.sharedPause {
+		return nil
+	}
+
+	var (
+		key = s.jobKeys(name).paused(name)
+		err error
+	)
+
+	if paused {
+		_, err = do(s.redisPool(), "SET", key, s.nodeID)
+	} else {
+		_, err = do(s.redisPool(), "DEL", key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not store paused state: %w", err)
+	}
+
+	return nil
+}
+
+// isPaused reports if the job is paused in this process or, when using
+// WithSharedPause, in Redis. If the state can't be read from Redis the job is
+// not considered paused.
+func (s *Schedule) isPaused(name string) bool {
+	s.mu.Lock()
+	for _, job := range s.jobs {
+		if job.Name == name && job.paused {
+			s.mu.Unlock()
+			return true
+		}
+	}
+	s.mu.Unlock()
+
+	if !s.sharedPause {
+		return false
+	}
+
+	paused, err := redis.Bool(do(s.redisPool(), "EXISTS", s.jobKeys(name).paused(name)))
+	if err != nil {
+		s.logger.Error(err, "could not get paused state", s.jobFields(name, "error")...)
+		return false
+	}
+
+	return paused
+}
@@ -0,0 +1,392 @@
+package distcron
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redsync/redsync"
+	"github.com/gomodule/redigo/redis"
+)
+
+// Ping will check that the Redis database is reachable with the configured
+// options and return ErrRedisUnavailable if it's not. If multiple endpoints are
+// configured with WithRedisEndpoints every endpoint must be reachable. This is
+// the same check that is made when starting the schedule and can be used for
+// health checks both before and after the schedule is started.
+func (s *Schedule) Ping() error {
+	for _, pool := range s.redisPools() {
+		if _, err := do(pool, "PING"); err != nil {
+			return fmt.Errorf("%w: %v", ErrRedisUnavailable, err)
+		}
+	}
+
+	return nil
+}
+
+// IsJobRunning reports if the lock for the job is held by any process and the
+// ID of the node holding it. The state is read from Redis so this works in any
+// process, even if the schedule isn't running. Jobs sharing a lock group with
+// WithLockGroup are reported as running if any job in the group is running. The
+// node ID is empty if the status was too large, see WithStatusMaxSize.
+func (s *Schedule) IsJobRunning(name string) (bool, string, error) {
+	s.mu.Lock()
+	lockName := name
+
+	for _, job := range s.jobs {
+		if job.Name == name {
+			lockName = job.lockName()
+		}
+	}
+	s.mu.Unlock()
+
+	value, err := redis.Bytes(do(s.redisPool(), "GET", s.jobKeys(lockName).status(lockName)))
+	if errors.Is(err, redis.ErrNil) {
+		return false, "", nil
+	}
+
+	if err != nil {
+		return false, "", fmt.Errorf("could not get job key: %w", err)
+	}
+
+	return true, holderFromStatus(s.decodeStatus, value), nil
+}
+
+// startupPing will ping Redis and retry with the delay set with
+// WithStartupPing until it succeeds, the number of retries is reached or the
+// context is cancelled.
+func (s *Schedule) startupPing(ctx context.Context) error {
+	delay := s.startupPingDelay
+
+	for attempt := 1; ; attempt++ {
+		err := s.Ping()
+		if err == nil || attempt > s.startupPingRetries {
+			return err
+		}
+
+		s.logger.Info("redis not reachable, retrying", "attempt", attempt, "error", err.Error())
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+
+		delay *= 2
+	}
+}
+
+// WithRedisPool sets the pool used for all Redis operations, both the redsync
+// mutexes and the job keys, instead of creating one from the configured
+// options. This makes it possible to share a pool with the rest of an
+// application. The host, port, database, credentials, TLS and pool options are
+// ignored when a pool is set and the pool is never closed by distcron. A nil
+// pool or a pool without a Dial function is reported as an error when the
+// schedule is started.
+func (s *Schedule) WithRedisPool(pool *redis.Pool) *Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pool == nil || pool.Dial == nil {
+		s.errs = append(s.errs, errors.New("redis pool must not be nil and must have a dial function"))
+		return s
+	}
+
+	s.userPool = pool
+
+	return s
+}
+
+// redisPool will return the Redis pool and create it from the configured
+// options if it doesn't exist yet.
+func (s *Schedule) redisPool() *redis.Pool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userPool != nil {
+		return s.userPool
+	}
+
+	if s.pool != nil {
+		return s.pool
+	}
+
+	s.applyRedisURL()
+
+	if len(s.clusterNodes) > 0 {
+		cluster := s.newRedisCluster()
+
+		s.cluster = cluster
+		s.pool = s.newPool(func() (redis.Conn, error) {
+			return cluster.conn(), nil
+		}, false)
+
+		return s.pool
+	}
+
+	s.pool = s.newPool(s.dial, len(s.sentinelAddrs) > 0)
+
+	return s.pool
+}
+
+// redisPools returns the pools used for the redsync mutexes. The first pool is
+// always the one returned by redisPool followed by one pool for each additional
+// endpoint set with WithRedisEndpoints.
+func (s *Schedule) redisPools() []redsync.Pool {
+	pools := []redsync.Pool{s.redisPool()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quorumPools == nil {
+		for _, endpoint := range s.quorumEndpoints {
+			e := endpoint
+
+			s.quorumPools = append(s.quorumPools, s.newPool(func() (redis.Conn, error) {
+				return s.dialRedis("tcp", net.JoinHostPort(e.Host, strconv.Itoa(e.Port)), e)
+			}, false))
+		}
+	}
+
+	for _, pool := range s.quorumPools {
+		pools = append(pools, pool)
+	}
+
+	return pools
+}
+
+// newPool creates a pool with the configured limits using the dial function.
+// If sentinel is true every connection is verified to still be a master when
+// borrowed from the pool.
+func (s *Schedule) newPool(dial func() (redis.Conn, error), sentinel bool) *redis.Pool {
+	return &redis.Pool{
+		Dial:        dial,
+		MaxIdle:     s.maxIdle,
+		MaxActive:   s.maxActive,
+		IdleTimeout: s.idleTimeout,
+		Wait:        s.waitForConn,
+		TestOnBorrow: func(conn redis.Conn, lastUsed time.Time) error {
+			// When using Sentinel the master might have changed so every
+			// connection must be verified.
+			if sentinel {
+				return testRole(conn)
+			}
+
+			// Only check connections that have been idle for a while to
+			// detect connections closed by a restarted Redis.
+			if time.Since(lastUsed) < time.Minute {
+				return nil
+			}
+
+			_, err := conn.Do("PING")
+
+			return err
+		},
+	}
+}
+
+// parseRedisURL will parse and validate a Redis URL.
+func parseRedisURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The error from url.Parse includes the URL with any password so
+		// only the reason is kept.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("invalid redis url scheme: %s", u.Scheme)
+	}
+
+	if port := u.Port(); port != "" {
+		if _, err := strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("invalid redis url port: %s", port)
+		}
+	}
+
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis url database: %s", db)
+		}
+	}
+
+	return u, nil
+}
+
+// applyRedisURL will override the connection options with the values from the
+// Redis URL if one is set. The URL is validated when set so any parse errors
+// can be ignored.
+func (s *Schedule) applyRedisURL() {
+	if s.redisURL == nil {
+		return
+	}
+
+	u := s.redisURL
+
+	s.redisHost = "localhost"
+	if host := u.Hostname(); host != "" {
+		s.redisHost = host
+	}
+
+	s.redisPort = 6379
+	if port := u.Port(); port != "" {
+		s.redisPort, _ = strconv.Atoi(port)
+	}
+
+	s.redisDB = 0
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		s.redisDB, _ = strconv.Atoi(db)
+	}
+
+	s.redisUsername, s.redisPassword = "", ""
+	if u.User != nil {
+		s.redisUsername = u.User.Username()
+		s.redisPassword, _ = u.User.Password()
+	}
+
+	if u.Scheme == "rediss" && s.tlsConfig == nil {
+		s.tlsConfig = &tls.Config{}
+	} else if u.Scheme == "redis" {
+		s.tlsConfig = nil
+	}
+}
+
+// dial will connect to the configured Redis database, either over the Unix
+// socket, to the master returned by Sentinel or to the host and port.
+func (s *Schedule) dial() (redis.Conn, error) {
+	var (
+		network = "tcp"
+		address = net.JoinHostPort(s.redisHost, strconv.Itoa(s.redisPort))
+	)
+
+	switch {
+	case s.redisSocket != "":
+		// Check the path first since the error from dialing a missing
+		// socket doesn't tell what's wrong.
+		if _, err := os.Stat(s.redisSocket); err != nil {
+			return nil, fmt.Errorf("could not connect to redis socket: %w", err)
+		}
+
+		network, address = "unix", s.redisSocket
+	case len(s.sentinelAddrs) > 0:
+		master, err := s.masterAddress()
+		if err != nil {
+			return nil, err
+		}
+
+		address = master
+	}
+
+	return s.dialRedis(network, address, RedisEndpoint{
+		DB:        s.redisDB,
+		Username:  s.redisUsername,
+		Password:  s.redisPassword,
+		TLSConfig: s.tlsConfig,
+	})
+}
+
+// dialRedis will connect to the address, authenticate if a password is set for
+// the endpoint and select the database. The host and port of the endpoint are
+// not used. The credentials are never a part of any address to ensure they
+// won't end up in any log or error message.
+func (s *Schedule) dialRedis(network, address string, e RedisEndpoint) (redis.Conn, error) {
+	var (
+		dialer      = &net.Dialer{KeepAlive: 5 * time.Minute, Timeout: s.connectTimeout}
+		connectErr  error
+		dialOptions = []redis.DialOption{
+			redis.DialNetDial(func(network, addr string) (net.Conn, error) {
+				conn, err := dialer.Dial(network, addr)
+				connectErr = err
+
+				return conn, err
+			}),
+			redis.DialReadTimeout(s.readTimeout),
+			redis.DialWriteTimeout(s.writeTimeout),
+		}
+	)
+
+	if e.TLSConfig != nil {
+		dialOptions = append(
+			dialOptions,
+			redis.DialUseTLS(true),
+			redis.DialTLSConfig(e.TLSConfig),
+		)
+	}
+
+	conn, err := redis.Dial(network, address, dialOptions...)
+	if err != nil {
+		// If we did connect but the dial still failed the only thing that
+		// could have gone wrong is the TLS handshake.
+		if connectErr == nil && e.TLSConfig != nil {
+			return nil, fmt.Errorf("tls handshake with redis failed: %w", err)
+		}
+
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+
+	if e.Password != "" {
+		args := []interface{}{e.Password}
+		if e.Username != "" {
+			args = []interface{}{e.Username, e.Password}
+		}
+
+		if _, err := conn.Do("AUTH", args...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not authenticate to redis: %w", err)
+		}
+	}
+
+	if e.DB != 0 {
+		if _, err := conn.Do("SELECT", e.DB); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not select redis database %d: %w", e.DB, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// do will get a connection from the pool, run the command and return the
+// connection to the pool.
+func do(pool redsync.Pool, cmd string, args ...interface{}) (interface{}, error) {
+	conn := pool.Get()
+	defer conn.Close()
+
+	return conn.Do(cmd, args...)
+}
+
+// closeRedisPool will close all pools and remove them from the schedule so new
+// pools are created the next time they're used. A pool set with WithRedisPool
+// is owned by the caller and never closed.
+func (s *Schedule) closeRedisPool() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pool != nil {
+		_ = s.pool.Close()
+		s.pool = nil
+	}
+
+	if s.cluster != nil {
+		s.cluster.close()
+		s.cluster = nil
+	}
+
+	for _, pool := range s.quorumPools {
+		_ = pool.Close()
+	}
+
+	s.quorumPools = nil
+}
@@ -0,0 +1,83 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// WithReloadSignal sets a signal, e.g. SIGHUP, that will make Run call
+// ReloadEnabled. Signals are never handled by RunContext so call ReloadEnabled
+// directly when using a context. No reload signal is set by default.
+func (s *Schedule) WithReloadSignal(sig os.Signal) *Schedule {
+	s.reloadSignal = sig
+	return s
+}
+
+// ReloadEnabled will read the enabled flag for every job from Redis and enable
+// or disable the jobs accordingly, see EnableJob and DisableJob. The flag for a
+// job is stored in the key "ENABLED-<name>", with the prefix set with
+// WithKeyPrefix, and holds a boolean value such as "1", "0", "true" or "false".
+// Jobs without a flag are left as they are. All jobs are checked even if one
+// fails and the last error is returned.
+func (s *Schedule) ReloadEnabled() error {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.jobs))
+
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	s.mu.Unlock()
+
+	var lastErr error
+
+	for _, name := range names {
+		value, err := redis.String(do(s.redisPool(), "GET", s.jobKeys(name).enabled(name)))
+		if errors.Is(err, redis.ErrNil) {
+			continue
+		}
+
+		if err != nil {
+			s.logger.Error(err, "could not get enabled flag", "job", name, "node", s.nodeID)
+			lastErr = err
+
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			s.logger.Error(err, "invalid enabled flag", "job", name, "node", s.nodeID, "value", value)
+			lastErr = err
+
+			continue
+		}
+
+		s.setDisabled(name, !enabled)
+		s.logger.Info("reloaded enabled flag", "job", name, "node", s.nodeID, "enabled", enabled)
+	}
+
+	return lastErr
+}
+
+// handleReload will call ReloadEnabled every time the reload signal is caught
+// until the context is cancelled.
+func (s *Schedule) handleReload(ctx context.Context) {
+	reload := make(chan os.Signal, 1)
+
+	signal.Notify(reload, s.reloadSignal)
+	defer signal.Stop(reload)
+
+	for {
+		select {
+		case <-reload:
+			s.logger.Info("caught reload signal")
+			_ = s.ReloadEnabled()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
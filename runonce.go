@@ -0,0 +1,137 @@
+package distcron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// runOnceKey is the context key used to store the state of RunOnce.
+type runOnceKey struct{}
+
+// runOnceState holds the errors returned from the jobs started by RunOnce.
+type runOnceState struct {
+	mu   sync.Mutex
+	errs *multierror.Error
+}
+
+// RunOnce will run the jobs with the given names, or all jobs if no name is
+// given, once right away without starting the schedule and return when they're
+// finished. Each job still has to take the lock so a job already running in
+// another process is skipped, and the lock is released when the job is done
+// the same way as when running the schedule. Leader election and load
+// balancing are not used. This is useful when running distcron as a one-off
+// command, e.g. as a Kubernetes Job. The errors from the jobs and from taking
+// their locks are returned as a *multierror.Error holding one *JobError for
+// each failed job. If a name isn't added to the schedule ErrUnknownJob is
+// returned without running any job and if the schedule or another call to
+// RunOnce is running ErrAlreadyRunning is returned. Jobs added with AddOnceAt
+// run no matter their time and are removed from the schedule and marked as
+// done the same way as when triggered by the schedule.
+func (s *Schedule) RunOnce(names ...string) error {
+	return s.RunOnceContext(context.Background(), names...)
+}
+
+// RunOnceContext works like RunOnce but the passed context is passed to the
+// jobs and cancelling it will stop waiting for locks.
+func (s *Schedule) RunOnceContext(ctx context.Context, names ...string) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.stop != nil || s.runningOnce {
+		s.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+
+	s.setDefaults()
+
+	jobs, err := s.jobsByName(names)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.runningOnce = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.runningOnce = false
+		s.mu.Unlock()
+	}()
+
+	locker := s.locker
+	if locker == nil {
+		// The pool is only used while running the jobs.
+		defer s.closeRedisPool()
+
+		if !s.skipStartupPing {
+			if err := s.startupPing(ctx); err != nil {
+				return err
+			}
+		}
+
+		locker = s.newRedisLocker(ctx, s.redisPool(), s.redisPools())
+	}
+
+	var (
+		state = &runOnceState{}
+		wg    sync.WaitGroup
+	)
+
+	ctx = context.WithValue(ctx, runOnceKey{}, state)
+
+	for _, job := range jobs {
+		run := s.lock(ctx, locker, job)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+
+	wg.Wait()
+
+	return state.errs.ErrorOrNil()
+}
+
+// jobsByName returns the jobs with the given names or all jobs if no name is
+// given. This must be called while holding the lock.
+func (s *Schedule) jobsByName(names []string) ([]*Job, error) {
+	if len(names) == 0 {
+		return append([]*Job{}, s.jobs...), nil
+	}
+
+	jobs := make([]*Job, 0, len(names))
+
+	for _, name := range names {
+		found := false
+
+		for _, job := range s.jobs {
+			if job.Name == name {
+				jobs = append(jobs, job)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w %s", ErrUnknownJob, name)
+		}
+	}
+
+	return jobs, nil
+}
+
+// isRunOnce reports if the job is started by RunOnce.
+func isRunOnce(ctx context.Context) bool {
+	_, ok := ctx.Value(runOnceKey{}).(*runOnceState)
+	return ok
+}
@@ -0,0 +1,103 @@
+package distcron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunOnceClosesPool(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	s.AddJob("@yearly", "job", func() {})
+
+	if err := s.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.mu.Lock()
+	pool := s.pool
+	s.mu.Unlock()
+
+	if pool != nil {
+		t.Fatal("pool not closed after RunOnce")
+	}
+}
+
+func TestRunOnceWhileRunning(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+	)
+
+	s := New().
+		WithLocker(NewInMemoryLocker()).
+		AddJob("@yearly", "job", func() {
+			close(started)
+			<-unblock
+		})
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.RunOnce()
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job never started")
+	}
+
+	if err := s.RunOnce(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning from RunOnce, got %v", err)
+	}
+
+	if err := s.RunContext(context.Background()); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning from RunContext, got %v", err)
+	}
+
+	close(unblock)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The schedule can't be run once while it's running either.
+	stop := startSchedule(t, s)
+	defer stop()
+
+	if err := s.RunOnce(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning while running, got %v", err)
+	}
+}
+
+func TestRunOnceConsumesOnceJobs(t *testing.T) {
+	var (
+		locker = NewInMemoryLocker()
+		runs   int
+	)
+
+	s := New().
+		WithLocker(locker).
+		AddJob("@yearly", "job", func() {}).
+		AddOnceAt(time.Now().Add(time.Hour), "once", func() { runs++ })
+
+	if err := s.RunOnce(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if runs != 1 {
+		t.Fatalf("expected the once job to run, got %d runs", runs)
+	}
+
+	if len(s.jobs) != 1 {
+		t.Fatal("once job not removed after RunOnce")
+	}
+
+	if done, _ := locker.IsDone("once"); !done {
+		t.Fatal("once job not marked as done")
+	}
+}
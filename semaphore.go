@@ -0,0 +1,85 @@
+package distcron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Semaphore can be implemented by a Locker to allow a limited number of
+// processes to run a job at the same time, see WithMaxConcurrent. Both the
+// default Redis locker and the InMemoryLocker implement this interface.
+type Semaphore interface {
+	// AcquireSlot will try to acquire one of max slots for the job with the
+	// given name. If all slots are taken false will be returned. If a slot was
+	// acquired the release function must be called when the job is finished.
+	AcquireSlot(name string, max int) (bool, func(), error)
+}
+
+// AcquireSlot will acquire a slot for the job unless max slots are taken.
+func (l *InMemoryLocker) AcquireSlot(name string, max int) (bool, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.slots == nil {
+		l.slots = map[string]int{}
+	}
+
+	if l.slots[name] >= max {
+		return false, nil, nil
+	}
+
+	l.slots[name]++
+
+	return true, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.slots[name]--
+	}, nil
+}
+
+// AcquireSlot will add a unique member to a sorted set for the job if it holds
+// less than max members. The score of each member is the time it expires so
+// slots held by crashed processes are freed after the job TTL.
+func (l *redisLocker) AcquireSlot(name string, max int) (bool, func(), error) {
+	var (
+		key    = l.keys(name).slots(name)
+		member = fmt.Sprintf("%s-%d", l.nodeID, time.Now().UnixNano())
+		now    = time.Now().UnixNano() / int64(time.Millisecond)
+		expiry = "+inf"
+	)
+
+	if l.ttl > 0 {
+		expiry = fmt.Sprintf("%d", now+int64(l.ttl/time.Millisecond))
+	}
+
+	acquired, err := redis.Bool(l.script(acquireSlotScript, key, now, max, expiry, member))
+	if err != nil {
+		return false, nil, fmt.Errorf("could not acquire slot: %w", err)
+	}
+
+	if !acquired {
+		return false, nil, nil
+	}
+
+	return true, l.own(key+":"+member, func() {
+		if _, err := l.do("ZREM", key, member); err != nil {
+			l.logger.Error(err, "could not release job slot", "job", name, "node", l.nodeID)
+		}
+	}), nil
+}
+
+// acquireSlotScript will remove expired members and add the member with the
+// expiry as score if there are fewer than the max number of members.
+var acquireSlotScript = redis.NewScript(1, `
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+
+	if redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+		redis.call("ZADD", KEYS[1], ARGV[3], ARGV[4])
+		return 1
+	else
+		return 0
+	end
+`)
@@ -0,0 +1,94 @@
+package distcron
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+)
+
+// sentinelTimeout is the connect, read and write timeout used when asking
+// Sentinel for the current master.
+const sentinelTimeout = 5 * time.Second
+
+// WithSentinel will use Redis Sentinel to find the address of the current
+// master instead of connecting to a fixed host and port. The sentinels are
+// asked in order until one of them knows about the master. Each connection is
+// verified to still be connected to a master when taken from the pool so all
+// operations will move to the new master after a failover. The sentinels are
+// connected to without authentication or TLS, those options are only used for
+// the connection to the master.
+func (s *Schedule) WithSentinel(addrs []string, masterName string) *Schedule {
+	s.sentinelAddrs = addrs
+	s.sentinelMaster = masterName
+
+	return s
+}
+
+// masterAddress will ask the sentinels for the address of the current master.
+func (s *Schedule) masterAddress() (string, error) {
+	var result error
+
+	for _, addr := range s.sentinelAddrs {
+		master, err := queryMaster(addr, s.sentinelMaster)
+		if err == nil {
+			return master, nil
+		}
+
+		result = multierror.Append(result, fmt.Errorf("sentinel %s: %w", addr, err))
+	}
+
+	if result == nil {
+		return "", errors.New("no sentinel addresses configured")
+	}
+
+	return "", fmt.Errorf("could not get master %s from sentinel: %w", s.sentinelMaster, result)
+}
+
+// queryMaster will ask a single sentinel for the address of the master.
+func queryMaster(addr, masterName string) (string, error) {
+	conn, err := redis.DialTimeout("tcp", addr, sentinelTimeout, sentinelTimeout, sentinelTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close()
+
+	reply, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", masterName))
+	if err != nil {
+		return "", err
+	}
+
+	if len(reply) != 2 {
+		return "", fmt.Errorf("unexpected reply %v", reply)
+	}
+
+	return net.JoinHostPort(reply[0], reply[1]), nil
+}
+
+// testRole will ensure the connection is connected to a master. This is used
+// to detect connections to a master that has been demoted after a failover.
+func testRole(conn redis.Conn) error {
+	reply, err := redis.Values(conn.Do("ROLE"))
+	if err != nil {
+		return err
+	}
+
+	if len(reply) == 0 {
+		return errors.New("empty reply from ROLE")
+	}
+
+	role, err := redis.String(reply[0], nil)
+	if err != nil {
+		return err
+	}
+
+	if role != "master" {
+		return fmt.Errorf("connected to %s, not master", role)
+	}
+
+	return nil
+}
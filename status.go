@@ -0,0 +1,79 @@
+package distcron
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StatusInfo is the information written as the value of the job key while a job
+// is running. This makes it possible for external tools to see who runs a job
+// by reading Redis directly.
+type StatusInfo struct {
+	Name    string    `json:"name"`
+	NodeID  string    `json:"node_id"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+// WithStatusEncoder sets the functions used to encode the value of the job key
+// when a job is acquired and to decode it when reading who holds the job, e.g.
+// in IsJobRunning. A value that can't be decoded is used as the node ID as is.
+// The value is encoded as JSON by default. The information stored for LastRun
+// is not affected.
+func (s *Schedule) WithStatusEncoder(encode func(StatusInfo) ([]byte, error), decode func([]byte) (StatusInfo, error)) *Schedule {
+	s.encodeStatus = encode
+	s.decodeStatus = decode
+
+	return s
+}
+
+// statusMarker is the value written to the job key instead of the encoded
+// status when it's larger than the size set with WithStatusMaxSize.
+const statusMarker = "1"
+
+// WithStatusMaxSize sets the maximum size in bytes of the encoded value of the
+// job key. If the value set with WithStatusEncoder is larger a warning is logged
+// and the minimal marker "1" is written instead. This protects Redis from a
+// buggy encoder. Since the marker doesn't tell who holds the job the node ID is
+// reported as empty, e.g. by IsJobRunning, and the lock can't be told apart from
+// a lock written with the marker by another process when it's released or
+// extended. No limit is used by default.
+func (s *Schedule) WithStatusMaxSize(size int) *Schedule {
+	s.statusMaxSize = size
+	return s
+}
+
+// encodeStatusJSON is the default status encoder.
+func encodeStatusJSON(info StatusInfo) ([]byte, error) {
+	return json.Marshal(info)
+}
+
+// decodeStatusJSON is the default status decoder.
+func decodeStatusJSON(data []byte) (StatusInfo, error) {
+	var info StatusInfo
+
+	err := json.Unmarshal(data, &info)
+
+	return info, err
+}
+
+// holderFromStatus returns the node ID from the value of a job key. If the
+// value can't be decoded the value itself is returned since older versions
+// wrote only the node ID. The marker written for values that are too large
+// returns an empty string.
+func holderFromStatus(decode func([]byte) (StatusInfo, error), value []byte) string {
+	if string(value) == statusMarker {
+		return ""
+	}
+
+	if decode == nil {
+		decode = decodeStatusJSON
+	}
+
+	info, err := decode(value)
+	if err != nil || info.NodeID == "" {
+		return string(value)
+	}
+
+	return info.NodeID
+}
@@ -0,0 +1,31 @@
+package distcron
+
+import "testing"
+
+func TestStatusMaxSize(t *testing.T) {
+	mr, s := newTestSchedule(t)
+	defer mr.Close()
+
+	// The JSON encoded status is always larger than this.
+	s.WithStatusMaxSize(10)
+
+	ok, release, err := newTestLocker(s).Acquire("job")
+	if err != nil || !ok {
+		t.Fatalf("could not acquire lock: %v", err)
+	}
+
+	if value, _ := mr.Get(s.keys.status("job")); value != statusMarker {
+		t.Fatalf("expected the marker to be written, got %q", value)
+	}
+
+	running, node, err := s.IsJobRunning("job")
+	if err != nil || !running || node != "" {
+		t.Fatalf("expected running job without a node, got %t %q: %v", running, node, err)
+	}
+
+	release()
+
+	if mr.Exists(s.keys.status("job")) {
+		t.Fatal("job key not removed")
+	}
+}
@@ -0,0 +1,81 @@
+package distcron
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduleSummary is a snapshot of the schedule returned by Summary.
+type ScheduleSummary struct {
+	// Running is true if the schedule is started.
+	Running bool `json:"running"`
+
+	// JobCount is the number of jobs added to the schedule.
+	JobCount int `json:"job_count"`
+
+	// Jobs holds every job in the order they were added.
+	Jobs []JobSummary `json:"jobs"`
+
+	// RunningJobs holds the sorted names of the jobs currently running in
+	// this process.
+	RunningJobs []string `json:"running_jobs"`
+}
+
+// JobSummary is the summary of a single job.
+type JobSummary struct {
+	Name     string `json:"name"`
+	Spec     string `json:"spec,omitempty"`
+	Disabled bool   `json:"disabled"`
+	Paused   bool   `json:"paused"`
+	Running  bool   `json:"running"`
+
+	// NextRun is the next time the job is scheduled to run, see NextRuns.
+	// It's nil if the schedule isn't running or the job won't run again.
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+// Summary returns a snapshot of the schedule with every job, when they run next
+// and which jobs are running in this process. This is safe to call both before
+// and after the schedule is started, the next runs are only known while the
+// schedule is running. Only the state of this process is included, see
+// IsJobRunning to check if a job is running in any process.
+func (s *Schedule) Summary() ScheduleSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := ScheduleSummary{
+		Running:     s.stop != nil,
+		JobCount:    len(s.jobs),
+		Jobs:        make([]JobSummary, 0, len(s.jobs)),
+		RunningJobs: make([]string, 0, len(s.running)),
+	}
+
+	for _, job := range s.jobs {
+		_, running := s.running[job.Name]
+
+		j := JobSummary{
+			Name:     job.Name,
+			Spec:     job.Spec,
+			Disabled: job.disabled,
+			Paused:   job.paused,
+			Running:  running,
+		}
+
+		if s.cron != nil {
+			if entry := s.cron.Entry(job.entryID); entry.Valid() && !entry.Next.IsZero() {
+				next := s.nextRun(job, entry.Next)
+				j.NextRun = &next
+			}
+		}
+
+		summary.Jobs = append(summary.Jobs, j)
+	}
+
+	for name := range s.running {
+		summary.RunningJobs = append(summary.RunningJobs, name)
+	}
+
+	sort.Strings(summary.RunningJobs)
+
+	return summary
+}
@@ -0,0 +1,31 @@
+package distcron
+
+import "context"
+
+// Tracer is used to trace every run of a job, e.g. with OpenTelemetry. To keep
+// the dependencies to a minimum there is no built in support for any tracing
+// library.
+type Tracer interface {
+	// StartJob is called right before the job is invoked in the process that
+	// acquired the lock. The returned context is passed to the job, e.g. to
+	// jobs added with AddJobCtx, and the returned function is called with the
+	// error from the job, if any, when it's finished.
+	StartJob(ctx context.Context, name, nodeID string) (context.Context, func(err error))
+}
+
+// WithTracer sets a Tracer that will be called for every run of a job. Nothing
+// is traced by default.
+func (s *Schedule) WithTracer(t Tracer) *Schedule {
+	s.tracer = t
+	return s
+}
+
+// startSpan will start tracing the job if a Tracer is set. The returned
+// function must always be called when the job is finished.
+func (s *Schedule) startSpan(ctx context.Context, name string) (context.Context, func(err error)) {
+	if s.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	return s.tracer.StartJob(ctx, name, s.nodeID)
+}